@@ -0,0 +1,82 @@
+package logger_test
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/berquerant/logger"
+)
+
+func discardStandardLog(b *testing.B) {
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(w) })
+}
+
+func BenchmarkNewDefault(b *testing.B) {
+	discardStandardLog(b)
+	l := logger.NewDefault(logger.Linfo)
+
+	b.Run("message", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info("request done")
+		}
+	})
+	b.Run("args", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Info("request %s done in %d ms", "/api/users", 12)
+		}
+	})
+	b.Run("filtered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug("request done")
+		}
+	})
+	b.Run("parallel", func(b *testing.B) {
+		b.ReportAllocs()
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				l.Info("request done")
+			}
+		})
+	})
+}
+
+func BenchmarkGlobal(b *testing.B) {
+	discardStandardLog(b)
+	g := logger.G()
+
+	b.Run("message", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.Info("request done")
+		}
+	})
+	b.Run("args", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			g.Info("request %s done in %d ms", "/api/users", 12)
+		}
+	})
+}
+
+func BenchmarkPrefixChain(b *testing.B) {
+	discardStandardLog(b)
+	// the default path before the pooling
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(
+			logger.LogLevelFilter(logger.Linfo).
+				Next(logger.SanitizeMapper).
+				Next(logger.LogLevelToPrefixMapper).
+				Next(logger.StandardLogConsumer),
+		),
+	}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l.Info("request done")
+	}
+}
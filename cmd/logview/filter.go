@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/berquerant/logger"
+)
+
+// filter selects the records to print.
+type filter struct {
+	level  logger.Level
+	name   string
+	since  time.Time
+	until  time.Time
+	fields map[string]string
+}
+
+func (f *filter) match(r *logger.Record) bool {
+	if r.Level > f.level {
+		return false
+	}
+	if f.name != "" && r.Name != f.name {
+		return false
+	}
+	if !f.since.IsZero() && r.Time.Before(f.since) {
+		return false
+	}
+	if !f.until.IsZero() && r.Time.After(f.until) {
+		return false
+	}
+	for k, want := range f.fields {
+		v, ok := r.Field(k)
+		if !ok || fmt.Sprint(v) != want {
+			return false
+		}
+	}
+	return true
+}
+
+// parseTime accepts a time in RFC3339 or a duration before now like "10m".
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// fieldFlags is the repeatable key=value flag.
+type fieldFlags map[string]string
+
+func (f fieldFlags) String() string {
+	ss := make([]string, 0, len(f))
+	for k, v := range f {
+		ss = append(ss, k+"="+v)
+	}
+	return strings.Join(ss, ",")
+}
+
+func (f fieldFlags) Set(s string) error {
+	k, v, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return fmt.Errorf("want key=value but got %q", s)
+	}
+	f[k] = v
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	now := time.Date(2022, 9, 20, 10, 0, 0, 0, time.UTC)
+	r := &logger.Record{
+		Time:  now,
+		Level: logger.Lwarn,
+		Name:  "db",
+		Fields: []logger.Field{
+			{Key: "status", Value: "500"},
+		},
+	}
+
+	for _, tc := range []struct {
+		title  string
+		filter filter
+		want   bool
+	}{
+		{
+			title:  "level",
+			filter: filter{level: logger.Linfo},
+			want:   true,
+		},
+		{
+			title:  "lower level",
+			filter: filter{level: logger.Lerror},
+		},
+		{
+			title:  "name",
+			filter: filter{level: logger.Ltrace, name: "http"},
+		},
+		{
+			title:  "time range",
+			filter: filter{level: logger.Ltrace, since: now.Add(-time.Minute), until: now},
+			want:   true,
+		},
+		{
+			title:  "since",
+			filter: filter{level: logger.Ltrace, since: now.Add(time.Second)},
+		},
+		{
+			title:  "field",
+			filter: filter{level: logger.Ltrace, fields: map[string]string{"status": "500"}},
+			want:   true,
+		},
+		{
+			title:  "field mismatch",
+			filter: filter{level: logger.Ltrace, fields: map[string]string{"status": "200"}},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.filter.match(r))
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	now := time.Date(2022, 9, 20, 10, 0, 0, 0, time.UTC)
+	got, err := parseTime("10m", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(-10*time.Minute), got)
+	got, err = parseTime("2022-09-20T09:00:00Z", now)
+	assert.Nil(t, err)
+	assert.Equal(t, now.Add(-time.Hour), got)
+}
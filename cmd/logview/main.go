@@ -0,0 +1,207 @@
+// logview pretty-prints the JSON lines or the logfmt lines written by the logger.
+//
+// Usage:
+//
+//	logview [flags] [FILE...]
+//
+// Read stdin if no files are given.
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/berquerant/logger"
+)
+
+func main() {
+	var (
+		level      = flag.String("level", "trace", "show the records of the level or lower, like info")
+		name       = flag.String("name", "", "show the records of the name")
+		since      = flag.String("since", "", "show the records after the time, RFC3339 or a duration before now like 10m")
+		until      = flag.String("until", "", "show the records before the time, RFC3339 or a duration before now like 10m")
+		follow     = flag.Bool("f", false, "wait for the appended lines like tail -f")
+		color      = flag.String("color", "auto", "auto, always or never")
+		timeFormat = flag.String("time", "datetime", "time layout or the name of the layout like RFC3339, none to omit")
+		fields     = fieldFlags{}
+	)
+	flag.Var(fields, "field", "show the records with the field value, key=value, repeatable")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: logview [flags] [FILE...]\n\nRead stdin if no files are given.\n\nFlags:\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	f, err := newFilter(*level, *name, *since, *until, fields)
+	if err != nil {
+		fail(err)
+	}
+	colorMode, err := logger.ParseColorMode(*color)
+	if err != nil {
+		fail(err)
+	}
+	p := &printer{
+		w: os.Stdout,
+		enc: logger.NewTextEncoder(logger.EncoderConfig{
+			TimeFormat: logger.ParseTimeFormat(*timeFormat),
+			Theme:      colorMode.Theme(os.Stdout, logger.DefaultTheme),
+		}),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, flag.Args(), *follow, f, p); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "logview: %v\n", err)
+	os.Exit(1)
+}
+
+func newFilter(level, name, since, until string, fields map[string]string) (*filter, error) {
+	lv, err := logger.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s, err := parseTime(since, now)
+	if err != nil {
+		return nil, fmt.Errorf("since: %w", err)
+	}
+	u, err := parseTime(until, now)
+	if err != nil {
+		return nil, fmt.Errorf("until: %w", err)
+	}
+	return &filter{
+		level:  lv,
+		name:   name,
+		since:  s,
+		until:  u,
+		fields: fields,
+	}, nil
+}
+
+func run(ctx context.Context, paths []string, follow bool, f *filter, p *printer) error {
+	if len(paths) == 0 {
+		return view(ctx, os.Stdin, f, p)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		errs []error
+	)
+	for _, path := range paths {
+		path := path
+		process := func() error {
+			r, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer r.Close()
+			if follow {
+				return view(ctx, &tailReader{ctx: ctx, f: r}, f, p)
+			}
+			return view(ctx, r, f, p)
+		}
+		if !follow {
+			if err := process(); err != nil {
+				return err
+			}
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := process(); err != nil {
+				mux.Lock()
+				errs = append(errs, fmt.Errorf("%s: %w", path, err))
+				mux.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+func view(ctx context.Context, r io.Reader, f *filter, p *printer) error {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for sc.Scan() {
+		if ctx.Err() != nil {
+			return nil
+		}
+		line := sc.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		rec, err := logger.ParseRecord(line)
+		if err != nil {
+			if err := p.printRaw(line); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.match(rec) {
+			continue
+		}
+		if err := p.printRecord(rec); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+	return nil
+}
+
+// tailReader waits for the appended data at the end of the file until ctx is done.
+type tailReader struct {
+	ctx context.Context
+	f   *os.File
+}
+
+const tailInterval = 200 * time.Millisecond
+
+func (t *tailReader) Read(p []byte) (int, error) {
+	for {
+		n, err := t.f.Read(p)
+		if n > 0 || (err != nil && !errors.Is(err, io.EOF)) {
+			return n, err
+		}
+		t.rewindIfTruncated()
+		select {
+		case <-t.ctx.Done():
+			return 0, io.EOF
+		case <-time.After(tailInterval):
+		}
+	}
+}
+
+// rewindIfTruncated reads the file again from the start when the file is truncated, like log rotation by copytruncate.
+func (t *tailReader) rewindIfTruncated() {
+	fi, err := t.f.Stat()
+	if err != nil {
+		return
+	}
+	pos, err := t.f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return
+	}
+	if fi.Size() < pos {
+		_, _ = t.f.Seek(0, io.SeekStart)
+	}
+}
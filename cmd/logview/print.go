@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"sync"
+
+	"github.com/berquerant/logger"
+)
+
+// printer writes the records by the text encoder of the library.
+type printer struct {
+	mux sync.Mutex
+	w   io.Writer
+	enc logger.Encoder
+}
+
+func (p *printer) printRecord(r *logger.Record) error {
+	b, err := p.enc(r.Event())
+	if err != nil {
+		return err
+	}
+	return p.write(b)
+}
+
+// printRaw writes the line that is not a record.
+// The control characters are escaped not to be interpreted by the terminal.
+func (p *printer) printRaw(line []byte) error {
+	return p.write([]byte(logger.Sanitize(string(line))))
+}
+
+func (p *printer) write(b []byte) error {
+	p.mux.Lock()
+	defer p.mux.Unlock()
+	if _, err := p.w.Write(b); err != nil {
+		return err
+	}
+	_, err := p.w.Write([]byte{'\n'})
+	return err
+}
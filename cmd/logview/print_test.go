@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrinter(t *testing.T) {
+	enc, err := logger.NewEncoder(logger.FormatText, logger.EncoderConfig{})
+	assert.Nil(t, err)
+
+	t.Run("raw", func(t *testing.T) {
+		var b bytes.Buffer
+		p := &printer{w: &b, enc: enc}
+		assert.Nil(t, p.printRaw([]byte("plain \x1b[31mred\rover")))
+		assert.Equal(t, `plain \x1b[31mred\rover`+"\n", b.String())
+	})
+
+	t.Run("caller", func(t *testing.T) {
+		var b bytes.Buffer
+		p := &printer{w: &b, enc: enc}
+		r, err := logger.ParseRecord([]byte(`level=info msg=start caller=main.go:10 port=8080`))
+		assert.Nil(t, err)
+		assert.Nil(t, p.printRecord(r))
+		assert.Equal(t, "I | start caller=main.go:10 port=8080\n", b.String())
+	})
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Theme is the colors of the text encoder.
+// The colors are the parameters of the ANSI SGR sequence, like "31" for red or "1;31" for bold red.
+// Empty means no color.
+type Theme struct {
+	Levels map[Level]string
+	// Default is the color of the levels not in Levels.
+	Default string
+	Time    string
+	Name    string
+	Key     string
+	Caller  string
+}
+
+// DefaultTheme colors the levels, dims the time and the call site, and highlights the field keys.
+var DefaultTheme = &Theme{
+	Levels: map[Level]string{
+		Lerror: "1;31",
+		Lwarn:  "33",
+		Linfo:  "32",
+		Ldebug: "34",
+		Ltrace: "90",
+	},
+	Default: "35",
+	Time:    "2",
+	Name:    "1",
+	Key:     "36",
+	Caller:  "2",
+}
+
+func (t *Theme) level(level Level) string {
+	if t == nil {
+		return ""
+	}
+	if c, ok := t.Levels[level]; ok {
+		return c
+	}
+	return t.Default
+}
+
+func (t *Theme) get(f func(*Theme) string) string {
+	if t == nil {
+		return ""
+	}
+	return f(t)
+}
+
+// paint writes s colored by color, write s as is if color is empty.
+func paint(b *bytes.Buffer, color, s string) {
+	if color == "" {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString("\x1b[")
+	b.WriteString(color)
+	b.WriteByte('m')
+	b.WriteString(s)
+	b.WriteString("\x1b[0m")
+}
+
+// EnvNoColor disables the colors if not empty, see https://no-color.org/.
+const EnvNoColor = "NO_COLOR"
+
+// ColorEnabled returns true if w is a terminal and `EnvNoColor` is not set.
+func ColorEnabled(w io.Writer) bool {
+	if os.Getenv(EnvNoColor) != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+// ColorMode is when to color the output.
+type ColorMode string
+
+const (
+	ColorAuto   ColorMode = "auto"
+	ColorAlways ColorMode = "always"
+	ColorNever  ColorMode = "never"
+)
+
+var ErrInvalidColorMode = errors.New("InvalidColorMode")
+
+func ParseColorMode(s string) (ColorMode, error) {
+	switch m := ColorMode(strings.ToLower(strings.TrimSpace(s))); m {
+	case ColorAuto, ColorAlways, ColorNever:
+		return m, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrInvalidColorMode, s)
+	}
+}
+
+// Theme returns the theme for w by the mode.
+func (m ColorMode) Theme(w io.Writer, theme *Theme) *Theme {
+	switch m {
+	case ColorAlways:
+		return theme
+	case ColorAuto:
+		return AutoTheme(w, theme)
+	default:
+		return nil
+	}
+}
+
+// AutoTheme returns theme if `ColorEnabled(w)`, otherwise nil.
+func AutoTheme(w io.Writer, theme *Theme) *Theme {
+	if ColorEnabled(w) {
+		return theme
+	}
+	return nil
+}
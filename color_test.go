@@ -0,0 +1,79 @@
+package logger_test
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTextEncoderTheme(t *testing.T) {
+	ev := logger.WithExtra(
+		logger.NewEvent(logger.Lerror, "boom", nil),
+		logger.Extra{
+			Name: "db",
+			Fields: []logger.Field{
+				{Key: "code", Value: 1},
+			},
+		},
+	)
+	got, err := logger.NewTextEncoder(logger.EncoderConfig{
+		Theme: &logger.Theme{
+			Levels: map[logger.Level]string{
+				logger.Lerror: "31",
+			},
+			Key: "36",
+		},
+	})(ev)
+	assert.Nil(t, err)
+	assert.Equal(t, "\x1b[31mE |\x1b[0m [db] boom \x1b[36mcode=\x1b[0m1", string(got))
+}
+
+func TestColorMode(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	assert.Nil(t, err)
+	defer f.Close()
+
+	for _, tc := range []struct {
+		title string
+		mode  logger.ColorMode
+		w     io.Writer
+		want  *logger.Theme
+	}{
+		{
+			title: "always",
+			mode:  logger.ColorAlways,
+			w:     &bytes.Buffer{},
+			want:  logger.DefaultTheme,
+		},
+		{
+			title: "never",
+			mode:  logger.ColorNever,
+			w:     os.Stderr,
+		},
+		{
+			title: "auto buffer",
+			mode:  logger.ColorAuto,
+			w:     &bytes.Buffer{},
+		},
+		{
+			title: "auto file",
+			mode:  logger.ColorAuto,
+			w:     f,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.mode.Theme(tc.w, logger.DefaultTheme))
+		})
+	}
+
+	t.Run("no color", func(t *testing.T) {
+		t.Setenv(logger.EnvNoColor, "1")
+		assert.False(t, logger.ColorEnabled(os.Stderr))
+	})
+}
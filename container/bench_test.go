@@ -0,0 +1,36 @@
+package container_test
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func BenchmarkContext(b *testing.B) {
+	w := log.Writer()
+	log.SetOutput(io.Discard)
+	b.Cleanup(func() { log.SetOutput(w) })
+
+	c := container.New(
+		container.Map[string, any]{"request_id": "r-1", "user": "alice"},
+		logger.MustNewMapperFunc(logger.LogLevelFilter(logger.Linfo)).
+			Next(logger.LogLevelToPrefixMapper).
+			Next(logger.StandardLogConsumer),
+	)
+
+	b.Run("message", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c.L().Info("request done")
+		}
+	})
+	b.Run("filtered", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			c.L().Debug("request done")
+		}
+	})
+}
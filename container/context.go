@@ -2,6 +2,8 @@ package container
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
 
 	"github.com/berquerant/logger"
 )
@@ -10,35 +12,255 @@ type ctxKeyType string
 
 const ctxKey ctxKeyType = "ctxKeyValue"
 
+// FromContext returns the Context of ctx.
+// Panic if ctx has no Context, see `FromContextOK` and `FromContextOrDefault`.
 func FromContext(ctx context.Context) Context { return ctx.Value(ctxKey).(Context) }
 
+// FromContextOK returns the Context of ctx.
+// Return false if ctx has no Context.
+func FromContextOK(ctx context.Context) (Context, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	c, ok := ctx.Value(ctxKey).(Context)
+	return c, ok
+}
+
+// FromContextOrDefault returns the Context of ctx, or fallback if ctx has no Context.
+// If fallback is nil, returns a new child of `Default` like `Default().With()`,
+// the writes to its data are kept in the child, not shared by the other callers.
+func FromContextOrDefault(ctx context.Context, fallback Context) Context {
+	if c, ok := FromContextOK(ctx); ok {
+		return c
+	}
+	if fallback != nil {
+		return fallback
+	}
+	return Default().With()
+}
+
 // Context holds a request-scoped data and a logger to partial structured logging.
 type Context interface {
-	Data() Map[string, any]
+	// Data is the request-scoped data of this scope, safe for concurrent use.
+	// The fields inherited by `With` are not included, see `Snapshot`.
+	// `Default` has no data, the writes to its Data are discarded, use `With` or `Clone` to add data,
+	// `FromContextOrDefault` does it.
+	Data() *SyncMap[string, any]
+	// Snapshot returns the data including the inherited fields.
+	Snapshot() Map[string, any]
 	L() *logger.Logger
+	// Clone returns a new Context with a copy of `Snapshot`.
 	Clone() Context
+	// With returns a child Context with the fields like "key1", value1, "key2", value2.
+	// The child inherits the fields of this without copying, the child overrides the same keys.
+	// The writes to the child are not visible from this.
+	// A missing value is nil, non-string keys are converted by `fmt.Sprint`.
+	With(kv ...any) Context
+	// WithContext returns a new context with this.
+	// If the data are extracted from ctx, the new context has the child of this with them instead, see `WithExtractor`.
+	// This itself is unchanged.
 	WithContext(ctx context.Context) context.Context
+	// Recover is `logger.Logger.Recover` with the data.
+	//
+	//	defer c.Recover()
+	Recover()
+	// Go is `logger.Logger.Go` with the data.
+	Go(f func())
 }
 
 type contextImpl struct {
-	data   Map[string, any]
-	lgr    *logger.Logger
-	mapper logger.MapperFunc // for clone
+	data       *SyncMap[string, any]
+	hints      *SyncMap[string, *keyHint] // by the Keys, the name to the hint of the data of this scope
+	parent     *contextImpl
+	lgr        *logger.Logger
+	mapper     logger.MapperFunc // for clone
+	opts       []Option          // for clone
+	extractors []Extractor
+}
+
+// Option customizes the Context.
+type Option func(*options)
+
+type options struct {
+	redaction  *logger.Redaction
+	asFields   bool
+	extractors []Extractor
+}
+
+// AsFields attaches the data to the events as the fields instead of appending the JSON to the message,
+// so that the encoders decide how to write them, see `logger.EncoderConfig.Fields`.
+// The fields are in the order of the keys.
+func AsFields() Option {
+	return func(o *options) {
+		o.asFields = true
+	}
+}
+
+// WithRedaction redacts the data before writing it into the events.
+func WithRedaction(r *logger.Redaction) Option {
+	return func(o *options) {
+		o.redaction = r
+	}
+}
+
+// New returns a new Context with a copy of data.
+func New(data Map[string, any], mapper logger.MapperFunc, opts ...Option) Context {
+	return newContext(NewSyncMap(data), mapper, opts)
+}
+
+func newContext(data *SyncMap[string, any], mapper logger.MapperFunc, opts []Option) *contextImpl {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	c := &contextImpl{
+		data:       data,
+		hints:      &SyncMap[string, *keyHint]{},
+		mapper:     mapper,
+		opts:       opts,
+		extractors: o.extractors,
+	}
+	p := &ctxProxy{}
+	p.Proxy = logger.NewProxy(logger.MustNewMapperFunc(c.structMapper(o, p.consumeErr)).Next(mapper))
+	c.lgr = &logger.Logger{
+		Proxy: p,
+	}
+	return c
+}
+
+// ctxProxy keeps the err consumer to report the errors on encoding the data,
+// which happen after the mappers return.
+type ctxProxy struct {
+	logger.Proxy
+	errConsumer atomic.Pointer[func(error)]
 }
 
-func New(data Map[string, any], mapper logger.MapperFunc) Context {
-	return &contextImpl{
-		data: data,
-		lgr: &logger.Logger{
-			Proxy: logger.NewProxy(logger.MustNewMapperFunc(data.StructMapper).Next(mapper)),
-		},
-		mapper: mapper,
+func (p *ctxProxy) SetErrConsumer(errConsumer func(error)) {
+	p.errConsumer.Store(&errConsumer)
+	p.Proxy.SetErrConsumer(errConsumer)
+}
+
+func (p *ctxProxy) consumeErr(err error) {
+	if f := p.errConsumer.Load(); f != nil && *f != nil {
+		(*f)(err)
+	}
+}
+
+// structMapper appends the snapshot of the data at the time of logging,
+// so that the following writes to the data do not race with the encoding.
+// The data extracted from the context of the event override, see `logger.ContextOf`.
+// The hints of the keys apply, see `NewKey`.
+func (c *contextImpl) structMapper(o options, onErr func(error)) func(logger.Event) logger.Event {
+	return func(ev logger.Event) logger.Event {
+		snapshot := c.Snapshot()
+		if ctx := logger.ExtraOf(ev).Context; ctx != nil {
+			if m := extract(ctx, c.extractors); len(m) > 0 {
+				snapshot = snapshot.Merge(m, nil)
+			}
+		}
+		snapshot = applyHints(snapshot, c.hintSnapshot())
+		switch {
+		case o.asFields && o.redaction != nil:
+			return snapshot.RedactedFieldsMapper(o.redaction)(ev)
+		case o.asFields:
+			return snapshot.FieldsMapper(ev)
+		case o.redaction != nil:
+			return snapshot.redactedStructMapper(o.redaction, onErr)(ev)
+		default:
+			return snapshot.structMapper(onErr)(ev)
+		}
+	}
+}
+
+func (c *contextImpl) Data() *SyncMap[string, any] { return c.data }
+func (c *contextImpl) L() *logger.Logger           { return c.lgr }
+func (c *contextImpl) Go(f func())                 { c.lgr.Go(f) }
+
+func (c *contextImpl) Snapshot() Map[string, any] {
+	return scopeSnapshot(c, func(x *contextImpl) *SyncMap[string, any] { return x.data })
+}
+
+// hintSnapshot returns the hints of the Keys including the inherited ones.
+func (c *contextImpl) hintSnapshot() Map[string, *keyHint] {
+	return scopeSnapshot(c, func(x *contextImpl) *SyncMap[string, *keyHint] { return x.hints })
+}
+
+// scopeSnapshot merges the maps of the scopes from the root to c.
+func scopeSnapshot[V any](c *contextImpl, scope func(*contextImpl) *SyncMap[string, V]) Map[string, V] {
+	if c.parent == nil {
+		return scope(c).Snapshot()
+	}
+	var (
+		scopes []Map[string, V]
+		size   int
+	)
+	for x := c; x != nil; x = x.parent {
+		s := scope(x).Snapshot()
+		scopes = append(scopes, s)
+		size += len(s)
+	}
+	m := make(Map[string, V], size)
+	// the root first, the children override
+	for i := len(scopes) - 1; i >= 0; i-- {
+		for k, v := range scopes[i] {
+			m[k] = v
+		}
+	}
+	return m
+}
+
+func (c *contextImpl) Clone() Context {
+	var x *contextImpl
+	if c.parent == nil {
+		x = newContext(c.data.Clone(), c.mapper, c.opts)
+	} else {
+		x = newContext(NewSyncMap(c.Snapshot()), c.mapper, c.opts)
+	}
+	x.hints = NewSyncMap(c.hintSnapshot())
+	return x
+}
+
+func (c *contextImpl) With(kv ...any) Context { return c.child(kvMap(kv)) }
+
+func (c *contextImpl) child(data Map[string, any]) *contextImpl {
+	child := newContext(NewSyncMap(data), c.mapper, c.opts)
+	child.parent = c
+	return child
+}
+
+func kvMap(kv []any) Map[string, any] {
+	m := make(Map[string, any], (len(kv)+1)/2)
+	for i := 0; i < len(kv); i += 2 {
+		key, ok := kv[i].(string)
+		if !ok {
+			key = fmt.Sprint(kv[i])
+		}
+		var value any
+		if i+1 < len(kv) {
+			value = kv[i+1]
+		}
+		m[key] = value
+	}
+	return m
+}
+
+// WithFields returns a new context with the child Context of the Context of ctx, see `Context.With`.
+// If ctx has no Context, the child of `Default`.
+func WithFields(ctx context.Context, kv ...any) context.Context {
+	return FromContextOrDefault(ctx, nil).With(kv...).WithContext(ctx)
+}
+
+func (c *contextImpl) Recover() {
+	// recover works only in the deferred function itself
+	if r := recover(); r != nil {
+		c.lgr.HandlePanic(r)
 	}
 }
 
-func (c *contextImpl) Data() Map[string, any] { return c.data }
-func (c *contextImpl) L() *logger.Logger      { return c.lgr }
-func (c *contextImpl) Clone() Context         { return New(c.data.Clone(), c.mapper) }
 func (c *contextImpl) WithContext(ctx context.Context) context.Context {
+	if m := extract(ctx, c.extractors); len(m) > 0 {
+		// the child, not to leak the data of ctx into this shared by the other contexts
+		return context.WithValue(ctx, ctxKey, c.child(m))
+	}
 	return context.WithValue(ctx, ctxKey, c)
 }
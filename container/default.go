@@ -0,0 +1,76 @@
+package container
+
+import (
+	"context"
+	"sync"
+
+	"github.com/berquerant/logger"
+)
+
+// globalProxy passes the events to the global logger, see `logger.G`.
+type globalProxy struct{}
+
+func (globalProxy) Put(ev logger.Event)                    { logger.G().Put(ev) }
+func (globalProxy) SetErrConsumer(errConsumer func(error)) { logger.G().SetErrConsumer(errConsumer) }
+func (globalProxy) Enabled(level logger.Level) bool        { return logger.G().Enabled(level) }
+
+func globalMapper(ev logger.Event) (logger.Event, error) {
+	globalProxy{}.Put(ev)
+	return ev, nil
+}
+
+// defaultContext writes the events by the global logger without data.
+type defaultContext struct {
+	lgr *logger.Logger
+}
+
+func newDefaultContext() *defaultContext {
+	return &defaultContext{
+		lgr: &logger.Logger{
+			Proxy: globalProxy{},
+		},
+	}
+}
+
+// Data returns a new empty map every time, the default has no data.
+func (c *defaultContext) Data() *SyncMap[string, any] { return &SyncMap[string, any]{} }
+func (c *defaultContext) L() *logger.Logger           { return c.lgr }
+func (c *defaultContext) Clone() Context              { return New(nil, globalMapper) }
+func (c *defaultContext) Go(f func())                 { c.lgr.Go(f) }
+func (c *defaultContext) Snapshot() Map[string, any]  { return nil }
+func (c *defaultContext) With(kv ...any) Context      { return New(kvMap(kv), globalMapper) }
+
+func (c *defaultContext) Recover() {
+	if r := recover(); r != nil {
+		c.lgr.HandlePanic(r)
+	}
+}
+
+func (c *defaultContext) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKey, c)
+}
+
+var (
+	defaultMux sync.RWMutex
+	defaultCtx Context = newDefaultContext()
+)
+
+// Default returns the Context used when the context has no Context, see `FromContextOrDefault`.
+// The initial one writes the events by the global logger `logger.G` without data,
+// the writes to its `Context.Data` are discarded, Clone it or use `Context.With` to add data.
+func Default() Context {
+	defaultMux.RLock()
+	defer defaultMux.RUnlock()
+	return defaultCtx
+}
+
+// SetDefault replaces the Context returned by `Default`.
+// nil restores the initial one.
+func SetDefault(c Context) {
+	if c == nil {
+		c = newDefaultContext()
+	}
+	defaultMux.Lock()
+	defer defaultMux.Unlock()
+	defaultCtx = c
+}
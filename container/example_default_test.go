@@ -0,0 +1,38 @@
+package container_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleFromContextOrDefault() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	ctx := context.TODO()
+	_, ok := container.FromContextOK(ctx)
+	fmt.Println(ok)
+
+	c := container.FromContextOrDefault(ctx, nil)
+	c.Data().Set("Path", "/a") // kept in the child of the default
+	c.L().Info("no context installed")
+
+	container.FromContextOrDefault(ctx, nil).L().Info("another caller")
+
+	c = c.Clone()
+	c.Data().Set("RequestID", "stone1")
+	c.L().Info("cloned")
+
+	_, ok = container.FromContextOK(c.WithContext(ctx))
+	fmt.Println(ok)
+	// Output:
+	// false
+	// I | no context installed | {"Path":"/a"}
+	// I | another caller | {}
+	// I | cloned | {"Path":"/a","RequestID":"stone1"}
+	// true
+}
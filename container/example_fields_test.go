@@ -0,0 +1,55 @@
+package container_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleAsFields() {
+	for _, f := range []logger.Format{logger.FormatText, logger.FormatJSON, logger.FormatLogfmt} {
+		enc, err := logger.NewEncoder(f, logger.EncoderConfig{})
+		if err != nil {
+			panic(err)
+		}
+		c := container.New(map[string]any{
+			"RequestID": "stone1",
+			"Attempt":   2,
+		}, logger.NewWriterConsumer(os.Stdout, enc), container.AsFields())
+		c.L().Info("start")
+	}
+
+	enc, err := logger.NewEncoder(logger.FormatJSON, logger.EncoderConfig{
+		Fields: logger.FieldsJSON,
+	})
+	if err != nil {
+		panic(err)
+	}
+	c := container.New(map[string]any{
+		"RequestID": "stone1",
+	}, logger.NewWriterConsumer(os.Stdout, enc), container.AsFields())
+	c.L().Info("nested")
+	// Output:
+	// I | start Attempt=2 RequestID=stone1
+	// {"level":"info","msg":"start","Attempt":2,"RequestID":"stone1"}
+	// level=info msg=start Attempt=2 RequestID=stone1
+	// {"level":"info","msg":"nested","fields":{"RequestID":"stone1"}}
+}
+
+func ExampleContext_marshalError() {
+	c := container.New(map[string]any{
+		"f": func() {},
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(func(ev logger.Event) {
+			fmt.Printf(ev.Format()+"\n", ev.Args()...)
+		}))
+	c.L().SetErrConsumer(func(err error) {
+		fmt.Println(err)
+	})
+	c.L().Info("start")
+	// Output:
+	// MarshalData: json: unsupported type: func()
+	// I | start
+}
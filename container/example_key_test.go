@@ -0,0 +1,40 @@
+package container_test
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+var (
+	keyRequestID = container.NewKey[string]("request_id")
+	keyToken     = container.NewKey[string]("token", container.RedactKey())
+	keyStartedAt = container.NewKey[time.Time]("started_at", container.FormatKey(func(t time.Time) any {
+		return t.Unix()
+	}))
+)
+
+func ExampleKey() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	c := container.New(nil, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer))
+	keyRequestID.Set(c, "stone1")
+	keyToken.Set(c, "secret")
+	keyStartedAt.Set(c, time.Unix(1663632000, 0))
+
+	id, ok := keyRequestID.Get(c)
+	fmt.Println(id, ok)
+	_, ok = keyStartedAt.Get(keyRequestID.With(c, "stone2"))
+	fmt.Println(ok)
+	c.L().Info("start")
+	// Output:
+	// stone1 true
+	// true
+	// I | start | {"request_id":"stone1","started_at":1663632000,"token":"[REDACTED]"}
+}
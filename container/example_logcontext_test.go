@@ -0,0 +1,37 @@
+package container_test
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleWithExtractor() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	type tenantKey struct{}
+	tenant := func(ctx context.Context) container.Map[string, any] {
+		if x, ok := ctx.Value(tenantKey{}).(string); ok {
+			return container.Map[string, any]{"tenant": x}
+		}
+		return nil
+	}
+	c := container.New(map[string]any{
+		"RequestID": "stone1",
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer), container.WithExtractor(tenant, container.TraceParentExtractor()))
+
+	ctx := container.WithTraceParent(context.TODO(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx = context.WithValue(ctx, tenantKey{}, "t1")
+	c.L().Info("without context")
+	c.L().InfoContext(ctx, "with context")
+	c.L().Info("not kept")
+	// Output:
+	// I | without context | {"RequestID":"stone1"}
+	// I | with context | {"RequestID":"stone1","span_id":"00f067aa0ba902b7","tenant":"t1","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}
+	// I | not kept | {"RequestID":"stone1"}
+}
@@ -0,0 +1,28 @@
+package container_test
+
+import (
+	"log"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleContext_Recover() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	c := container.New(map[string]any{
+		"RequestID": "stone1",
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer))
+
+	func() {
+		defer c.Recover()
+		panic("boom")
+	}()
+	c.L().Info("recovered")
+	// Output:
+	// E | panic: boom | {"RequestID":"stone1"}
+	// I | recovered | {"RequestID":"stone1"}
+}
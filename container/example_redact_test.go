@@ -0,0 +1,34 @@
+package container_test
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleWithRedaction() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	r, err := logger.NewRedaction(logger.RedactConfig{
+		Keys: []string{"Authorization"},
+	})
+	if err != nil {
+		panic(err)
+	}
+	ctx := container.New(map[string]any{
+		"RequestID":     "stone1",
+		"Authorization": "Bearer xyz",
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer), container.WithRedaction(r)).WithContext(context.TODO())
+
+	c := container.FromContext(ctx)
+	c.L().Info("first")
+	c.Clone().L().Info("cloned")
+	// Output:
+	// I | first | {"Authorization":"[REDACTED]","RequestID":"stone1"}
+	// I | cloned | {"Authorization":"[REDACTED]","RequestID":"stone1"}
+}
@@ -0,0 +1,26 @@
+package container_test
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleTraceParentExtractor() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	// like the traceparent header of the incoming request
+	ctx := container.WithTraceParent(context.TODO(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx = container.New(map[string]any{
+		"RequestID": "stone1",
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer), container.WithExtractor(container.TraceParentExtractor())).WithContext(ctx)
+
+	container.FromContext(ctx).L().Info("start")
+	// Output:
+	// I | start | {"RequestID":"stone1","span_id":"00f067aa0ba902b7","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}
+}
@@ -0,0 +1,38 @@
+package container_test
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+)
+
+func ExampleContext_With() {
+	log.SetFlags(0)
+	log.SetOutput(os.Stdout)
+
+	ctx := container.New(map[string]any{
+		"RequestID": "stone1",
+	}, logger.MustNewMapperFunc(logger.LogLevelToPrefixMapper).
+		Next(logger.StandardLogConsumer)).WithContext(context.TODO())
+
+	func(ctx context.Context) {
+		c := container.FromContext(ctx)
+		c.L().Info("operation")
+		c.Data().Set("Retry", 1)
+		c.L().Info("retry")
+
+		func(ctx context.Context) {
+			container.FromContext(ctx).L().Info("sub-step")
+		}(container.WithFields(ctx, "Step", "commit", "RequestID", "stone1-1"))
+	}(container.WithFields(ctx, "Op", "update"))
+
+	container.FromContext(ctx).L().Info("done")
+	// Output:
+	// I | operation | {"Op":"update","RequestID":"stone1"}
+	// I | retry | {"Op":"update","RequestID":"stone1","Retry":1}
+	// I | sub-step | {"Op":"update","RequestID":"stone1-1","Retry":1,"Step":"commit"}
+	// I | done | {"RequestID":"stone1"}
+}
@@ -0,0 +1,122 @@
+package container
+
+// Key is a typed key of the data of `Context`.
+//
+//	var RequestID = container.NewKey[string]("request_id")
+//
+//	RequestID.Set(c, "r-1")
+//	id, ok := RequestID.Get(c)
+type Key[T any] struct {
+	name string
+	hint *keyHint
+}
+
+// NewKey returns a new key, name is the key of the data and the encoded fields.
+// The hints are kept by the scope of the Context next to the data, see `Context.With`,
+// and apply to the value of the name in the scope and its children until the other Key sets the name.
+// The values written by `Context.Data` keep the hints of the name.
+// The hints need the Context made by `New`.
+func NewKey[T any](name string, hints ...KeyHint) Key[T] {
+	k := Key[T]{name: name}
+	if len(hints) > 0 {
+		k.hint = &keyHint{}
+		for _, x := range hints {
+			x(k.hint)
+		}
+	}
+	return k
+}
+
+func (k Key[T]) Name() string   { return k.name }
+func (k Key[T]) String() string { return k.name }
+
+// Set sets the value to the data of c.
+// The value is discarded if c is `Default`, see `Context.Data`.
+func (k Key[T]) Set(c Context, value T) {
+	// the hint first, not to write the value by the old hint
+	k.setHint(c)
+	c.Data().Set(k.name, value)
+}
+
+// Get returns the value of c, including the inherited fields.
+// Return false if c has no value or the value is not T.
+func (k Key[T]) Get(c Context) (T, bool) {
+	x, ok := c.Snapshot()[k.name].(T)
+	return x, ok
+}
+
+// With returns a child of c with the value, see `Context.With`.
+func (k Key[T]) With(c Context, value T) Context {
+	child := c.With(k.name, value)
+	k.setHint(child)
+	return child
+}
+
+// setHint puts the hints into the scope of c, nil too, to override the hints of the other Keys.
+func (k Key[T]) setHint(c Context) {
+	if x, ok := c.(*contextImpl); ok {
+		x.hints.Set(k.name, k.hint)
+	}
+}
+
+// KeyHint customizes how the value of the key is written into the events.
+type KeyHint func(*keyHint)
+
+type keyHint struct {
+	redact bool
+	format func(any) any
+}
+
+// RedactKey hides the value like "[REDACTED]".
+func RedactKey() KeyHint {
+	return func(h *keyHint) {
+		h.redact = true
+	}
+}
+
+// FormatKey converts the value into the one to encode, like time.Time into the unix time.
+func FormatKey[T any](f func(T) any) KeyHint {
+	return func(h *keyHint) {
+		h.format = func(v any) any {
+			if x, ok := v.(T); ok {
+				return f(x)
+			}
+			return v
+		}
+	}
+}
+
+const redactedValue = "[REDACTED]"
+
+func (h *keyHint) render(v any) any {
+	switch {
+	case h.redact:
+		return redactedValue
+	case h.format != nil:
+		return h.format(v)
+	default:
+		return v
+	}
+}
+
+// applyHints returns the data with the hints rendered, m itself if no hints apply.
+func applyHints(m Map[string, any], hints Map[string, *keyHint]) Map[string, any] {
+	var c Map[string, any]
+	for k, h := range hints {
+		if h == nil {
+			continue
+		}
+		v, ok := m[k]
+		if !ok {
+			continue
+		}
+		if c == nil {
+			c = m.Clone()
+		}
+		c[k] = h.render(v)
+	}
+	if c == nil {
+		return m
+	}
+	return c
+}
@@ -0,0 +1,59 @@
+package container_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeyHintsPerKey(t *testing.T) {
+	var (
+		secret = container.NewKey[string]("token", container.RedactKey())
+		plain  = container.NewKey[string]("token")
+		unix   = container.NewKey[time.Time]("at", container.FormatKey(func(t time.Time) any { return t.Unix() }))
+		text   = container.NewKey[string]("at", container.FormatKey(func(s string) any { return "at " + s }))
+	)
+
+	var got string
+	c := container.New(nil, logger.MustNewMapperFunc(func(ev logger.Event) {
+		got = fmt.Sprintf(ev.Format(), ev.Args()...)
+	}))
+	log := func(c container.Context) string {
+		c.L().Info("m")
+		return got
+	}
+
+	secret.Set(c, "s1")
+	unix.Set(c, time.Unix(10, 0))
+	assert.Equal(t, `m | {"at":10,"token":"[REDACTED]"}`, log(c))
+	v, ok := secret.Get(c)
+	assert.True(t, ok)
+	assert.Equal(t, "s1", v)
+
+	plain.Set(c, "p1")
+	text.Set(c, "noon")
+	assert.Equal(t, `m | {"at":"at noon","token":"p1"}`, log(c))
+
+	c.Data().Set("token", "raw")
+	assert.Equal(t, `m | {"at":"at noon","token":"raw"}`, log(c))
+
+	assert.Equal(t, `m | {"at":"at noon","token":"[REDACTED]"}`, log(secret.With(c, "s2")))
+	_, ok = unix.Get(c)
+	assert.False(t, ok)
+
+	t.Run("raw data", func(t *testing.T) {
+		secret.Set(c, "s3")
+		v, _ := c.Data().Get("token")
+		// the data keep the plain values, the hints apply on logging
+		assert.Equal(t, "s3", v)
+		assert.Equal(t, `m | {"at":"at noon","token":"[REDACTED]"}`, log(c))
+		c.Data().Set("token", "s4")
+		assert.Equal(t, `m | {"at":"at noon","token":"[REDACTED]"}`, log(c), "keep the hint of the name")
+		assert.Equal(t, `m | {"at":"at noon","token":"[REDACTED]"}`, log(c.Clone()))
+		assert.Equal(t, `m | {"at":"at noon","token":"p2"}`, log(plain.With(c, "p2")))
+	})
+}
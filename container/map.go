@@ -2,6 +2,10 @@ package container
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
 
 	"github.com/berquerant/logger"
 )
@@ -24,14 +28,23 @@ func UpdateMap[K comparable, V any](a, b map[K]V) map[K]V {
 	return c
 }
 
+// Map is a map with the helpers.
+//
+// The zero value (nil) is an empty map ready to use.
+// Set allocates the map if nil, so use the returned map like append:
+//
+//	var m Map[string, any]
+//	m = m.Set("key", "value")
 type Map[K comparable, V any] map[K]V
 
-func NewMap[K comparable, V any]() Map[K, V] {
-	var m Map[K, V]
-	return m
-}
+// NewMap returns a new empty map.
+func NewMap[K comparable, V any]() Map[K, V] { return Map[K, V]{} }
 
+// Set sets the value and returns the map, a new map if m is nil.
 func (m Map[K, V]) Set(key K, value V) Map[K, V] {
+	if m == nil {
+		m = Map[K, V]{}
+	}
 	m[key] = value
 	return m
 }
@@ -41,14 +54,179 @@ func (m Map[K, V]) Get(key K) (V, bool) {
 	return v, ok
 }
 
+// Delete deletes the key and returns the map.
+func (m Map[K, V]) Delete(key K) Map[K, V] {
+	delete(m, key)
+	return m
+}
+
+func (m Map[K, V]) Has(key K) bool {
+	_, ok := m[key]
+	return ok
+}
+
+func (m Map[K, V]) Len() int { return len(m) }
+
+// Keys returns the keys in ascending order.
+// The keys other than the numbers and the strings are ordered by their string representations.
+func (m Map[K, V]) Keys() []K {
+	keys := make([]K, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sortKeys(keys)
+	return keys
+}
+
+// Range calls f for each key and value in the order of `Keys` until f returns false.
+func (m Map[K, V]) Range(f func(key K, value V) bool) {
+	for _, k := range m.Keys() {
+		if !f(k, m[k]) {
+			return
+		}
+	}
+}
+
+// ConflictFunc resolves the value of the key that both maps have.
+type ConflictFunc[K comparable, V any] func(key K, current, other V) V
+
+// Merge returns a new map with the keys and values of m and other.
+// resolve decides the value of the key that both have, nil means that other wins, same as `Update`.
+func (m Map[K, V]) Merge(other Map[K, V], resolve ConflictFunc[K, V]) Map[K, V] {
+	c := m.Clone()
+	for k, v := range other {
+		if x, ok := c[k]; ok && resolve != nil {
+			v = resolve(k, x, v)
+		}
+		c[k] = v
+	}
+	return c
+}
+
+// Filter returns a new map with the keys and values that f returns true.
+func (m Map[K, V]) Filter(f func(key K, value V) bool) Map[K, V] {
+	c := make(Map[K, V], len(m))
+	for k, v := range m {
+		if f(k, v) {
+			c[k] = v
+		}
+	}
+	return c
+}
+
 func (m Map[K, V]) Update(other Map[K, V]) Map[K, V] { return Map[K, V](UpdateMap(m, other)) }
 func (m Map[K, V]) Clone() Map[K, V]                 { return UpdateMap(m, nil) }
 
-// StructMapper appends the map as JSON at the tail.
-func (m Map[K, V]) StructMapper(ev logger.Event) logger.Event {
-	b, err := json.Marshal(m)
+func sortKeys[K comparable](keys []K) {
+	if len(keys) < 2 {
+		return
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		a, b := reflect.ValueOf(keys[i]), reflect.ValueOf(keys[j])
+		switch a.Kind() {
+		case reflect.String:
+			return a.String() < b.String()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return a.Int() < b.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+			return a.Uint() < b.Uint()
+		case reflect.Float32, reflect.Float64:
+			return a.Float() < b.Float()
+		default:
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		}
+	})
+}
+
+// Fields returns the keys and values as the fields in the order of `Keys`.
+// The keys other than the strings are converted by `fmt.Sprint`.
+func (m Map[K, V]) Fields() []logger.Field {
+	fields := make([]logger.Field, 0, len(m))
+	m.Range(func(k K, v V) bool {
+		fields = append(fields, logger.Field{
+			Key:   keyString(k),
+			Value: v,
+		})
+		return true
+	})
+	return fields
+}
+
+func keyString(k any) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprint(k)
+}
+
+// FieldsMapper attaches the map to the event as the fields, see `logger.AddFields`.
+// The encoders decide how to write them, see `logger.EncoderConfig.Fields`.
+func (m Map[K, V]) FieldsMapper(ev logger.Event) logger.Event {
+	if len(m) == 0 {
+		return ev
+	}
+	return logger.AddFields(ev, m.Fields()...)
+}
+
+// RedactedFieldsMapper returns a FieldsMapper that redacts the values before attaching.
+func (m Map[K, V]) RedactedFieldsMapper(r *logger.Redaction) func(logger.Event) logger.Event {
+	return func(ev logger.Event) logger.Event {
+		if len(m) == 0 {
+			return ev
+		}
+		fields := m.Fields()
+		for i, f := range fields {
+			fields[i].Value = r.Value(f.Key, f.Value)
+		}
+		return logger.AddFields(ev, fields...)
+	}
+}
+
+var ErrMarshalData = errors.New("MarshalData")
+
+// StructMapper appends the map as JSON at the tail like "message | {"key":"value"}".
+// The JSON is encoded when the event is formatted, so the events dropped by the following filters cost no encoding.
+// Nothing is appended if the map cannot be encoded, see `FieldsMapper` to leave the rendering to the encoders.
+func (m Map[K, V]) StructMapper(ev logger.Event) logger.Event { return m.structMapper(nil)(ev) }
+
+func (m Map[K, V]) structMapper(onErr func(error)) func(logger.Event) logger.Event {
+	return newStructMapper(func() any { return m }, onErr)
+}
+
+// RedactedStructMapper returns a StructMapper that redacts the map before encoding.
+func (m Map[K, V]) RedactedStructMapper(r *logger.Redaction) func(logger.Event) logger.Event {
+	return m.redactedStructMapper(r, nil)
+}
+
+func (m Map[K, V]) redactedStructMapper(r *logger.Redaction, onErr func(error)) func(logger.Event) logger.Event {
+	return newStructMapper(func() any { return r.Value("", map[K]V(m)) }, onErr)
+}
+
+func newStructMapper(m func() any, onErr func(error)) func(logger.Event) logger.Event {
+	data := jsonSuffix{
+		value: m,
+		onErr: onErr,
+	}
+	return func(ev logger.Event) logger.Event {
+		return logger.DeriveEvent(ev, ev.Level(), ev.Format()+"%v", append(ev.Args(), data))
+	}
+}
+
+// jsonSuffix writes " | " and the JSON of the value when formatted.
+// It writes nothing on the encoding error and reports it to onErr.
+type jsonSuffix struct {
+	value func() any
+	onErr func(error)
+}
+
+func (s jsonSuffix) Format(f fmt.State, _ rune) {
+	b, err := json.Marshal(s.value())
 	if err != nil {
-		return logger.NewEvent(ev.Level(), ev.Format()+" | %v", append(ev.Args(), err))
+		if s.onErr != nil {
+			s.onErr(fmt.Errorf("%w: %v", ErrMarshalData, err))
+		}
+		return
 	}
-	return logger.NewEvent(ev.Level(), ev.Format()+" | %s", append(ev.Args(), b))
+	_, _ = f.Write([]byte(" | "))
+	_, _ = f.Write(b)
 }
@@ -76,3 +76,42 @@ func TestUpdateMap(t *testing.T) {
 		})
 	}
 }
+
+func TestMap(t *testing.T) {
+	t.Run("zero value", func(t *testing.T) {
+		var m container.Map[string, int]
+		assert.False(t, m.Has("x"))
+		assert.Equal(t, 0, m.Len())
+		assert.Equal(t, []string{}, m.Keys())
+		m = m.Set("x", 1)
+		assert.Equal(t, container.Map[string, int]{"x": 1}, m)
+		assert.NotNil(t, container.NewMap[string, int]().Set("y", 2))
+	})
+
+	m := container.Map[string, int]{"b": 2, "a": 1, "c": 3}
+
+	t.Run("keys and range", func(t *testing.T) {
+		assert.Equal(t, []string{"a", "b", "c"}, m.Keys())
+		assert.Equal(t, []int{3, 10, 20}, container.Map[int, bool]{20: true, 3: true, 10: false}.Keys())
+		var got []string
+		m.Range(func(k string, v int) bool {
+			got = append(got, k)
+			return k != "b"
+		})
+		assert.Equal(t, []string{"a", "b"}, got)
+	})
+
+	t.Run("merge", func(t *testing.T) {
+		other := container.Map[string, int]{"c": 30, "d": 4}
+		assert.Equal(t, container.Map[string, int]{"a": 1, "b": 2, "c": 30, "d": 4}, m.Merge(other, nil))
+		assert.Equal(t, container.Map[string, int]{"a": 1, "b": 2, "c": 33, "d": 4}, m.Merge(other, func(_ string, a, b int) int { return a + b }))
+		assert.Equal(t, 3, m.Len())
+	})
+
+	t.Run("filter and delete", func(t *testing.T) {
+		got := m.Filter(func(_ string, v int) bool { return v%2 == 1 })
+		assert.Equal(t, container.Map[string, int]{"a": 1, "c": 3}, got)
+		assert.Equal(t, container.Map[string, int]{"c": 3}, got.Delete("a"))
+		assert.Equal(t, 3, m.Len())
+	})
+}
@@ -0,0 +1,101 @@
+package container
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// SyncMap is a goroutine-safe Map by copy-on-write.
+// Every write replaces the whole map, so the snapshots are never modified and cost nothing.
+// Suited for the small request-scoped data, read at every log.
+//
+// The zero value is an empty map ready to use.
+type SyncMap[K comparable, V any] struct {
+	// mux serializes the writes
+	mux sync.Mutex
+	m   atomic.Pointer[Map[K, V]]
+}
+
+// NewSyncMap returns a new SyncMap with a copy of m.
+func NewSyncMap[K comparable, V any](m Map[K, V]) *SyncMap[K, V] {
+	var s SyncMap[K, V]
+	c := m.Clone()
+	s.m.Store(&c)
+	return &s
+}
+
+// Snapshot returns the current map.
+// The returned map must not be modified.
+func (s *SyncMap[K, V]) Snapshot() Map[K, V] {
+	if p := s.m.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// replace replaces the map with the new one made from the current map by f.
+func (s *SyncMap[K, V]) replace(f func(Map[K, V]) Map[K, V]) *SyncMap[K, V] {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	c := f(s.Snapshot())
+	s.m.Store(&c)
+	return s
+}
+
+// update replaces the map with a copy of the current map modified by f.
+func (s *SyncMap[K, V]) update(f func(Map[K, V])) *SyncMap[K, V] {
+	return s.replace(func(m Map[K, V]) Map[K, V] {
+		c := m.Clone()
+		f(c)
+		return c
+	})
+}
+
+func (s *SyncMap[K, V]) Set(key K, value V) *SyncMap[K, V] {
+	return s.update(func(m Map[K, V]) { m[key] = value })
+}
+
+func (s *SyncMap[K, V]) Get(key K) (V, bool) { return s.Snapshot().Get(key) }
+
+func (s *SyncMap[K, V]) Delete(key K) *SyncMap[K, V] {
+	if !s.Has(key) {
+		return s
+	}
+	return s.update(func(m Map[K, V]) { delete(m, key) })
+}
+
+func (s *SyncMap[K, V]) Has(key K) bool { return s.Snapshot().Has(key) }
+func (s *SyncMap[K, V]) Len() int       { return s.Snapshot().Len() }
+
+// Keys returns the keys of the snapshot in ascending order, see `Map.Keys`.
+func (s *SyncMap[K, V]) Keys() []K { return s.Snapshot().Keys() }
+
+// Range calls f for the snapshot, see `Map.Range`.
+func (s *SyncMap[K, V]) Range(f func(key K, value V) bool) { s.Snapshot().Range(f) }
+
+// Merge merges other into this, see `Map.Merge`.
+func (s *SyncMap[K, V]) Merge(other Map[K, V], resolve ConflictFunc[K, V]) *SyncMap[K, V] {
+	return s.replace(func(m Map[K, V]) Map[K, V] { return m.Merge(other, resolve) })
+}
+
+// Filter leaves the keys and values that f returns true, see `Map.Filter`.
+func (s *SyncMap[K, V]) Filter(f func(key K, value V) bool) *SyncMap[K, V] {
+	return s.replace(func(m Map[K, V]) Map[K, V] { return m.Filter(f) })
+}
+
+func (s *SyncMap[K, V]) Update(other Map[K, V]) *SyncMap[K, V] {
+	return s.update(func(m Map[K, V]) {
+		for k, v := range other {
+			m[k] = v
+		}
+	})
+}
+
+// Clone returns a new SyncMap sharing the current snapshot.
+func (s *SyncMap[K, V]) Clone() *SyncMap[K, V] {
+	var c SyncMap[K, V]
+	if p := s.m.Load(); p != nil {
+		c.m.Store(p)
+	}
+	return &c
+}
@@ -0,0 +1,72 @@
+package container_test
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyncMap(t *testing.T) {
+	var m container.SyncMap[string, int]
+	_, ok := m.Get("x")
+	assert.False(t, ok)
+
+	m.Set("x", 1).Set("y", 2)
+	snapshot := m.Snapshot()
+	c := m.Clone()
+	m.Update(container.Map[string, int]{"y": 3, "z": 4})
+
+	assert.Equal(t, container.Map[string, int]{"x": 1, "y": 2}, snapshot)
+	assert.Equal(t, container.Map[string, int]{"x": 1, "y": 2}, c.Snapshot())
+	assert.Equal(t, container.Map[string, int]{"x": 1, "y": 3, "z": 4}, m.Snapshot())
+}
+
+func TestContextConcurrentData(t *testing.T) {
+	var (
+		mux   sync.Mutex
+		lines []string
+		c     = container.New(nil, logger.MustNewMapperFunc(func(ev logger.Event) {
+			mux.Lock()
+			defer mux.Unlock()
+			lines = append(lines, fmt.Sprint(ev))
+		}))
+		wg sync.WaitGroup
+	)
+	for i := 0; i < 4; i++ {
+		i := i
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.Data().Set(fmt.Sprintf("k%d", i), j)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				c.L().Info("log")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 400, len(lines))
+	for _, x := range lines {
+		assert.True(t, strings.HasPrefix(x, "log | {"), x)
+	}
+}
+
+func TestSyncMapTrim(t *testing.T) {
+	var m container.SyncMap[string, any]
+	m.Merge(container.Map[string, any]{"user": "alice", "password": "p", "path": "/"}, nil)
+	snapshot := m.Snapshot()
+	m.Delete("password").Filter(func(k string, _ any) bool { return k != "path" })
+	assert.Equal(t, []string{"user"}, m.Keys())
+	assert.True(t, m.Has("user"))
+	assert.Equal(t, 1, m.Len())
+	assert.Equal(t, 3, snapshot.Len())
+}
@@ -0,0 +1,159 @@
+package container
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Extractor returns the data of ctx to write into the events, like the trace ids.
+// nil or empty means no data.
+type Extractor func(ctx context.Context) Map[string, any]
+
+// WithExtractor adds the extractors that write the data of context.Context into the data of the Context.
+// They are called by `Context.WithContext` for the child Context in the returned context, and by the log calls with the context like `logger.Logger.InfoContext`
+// for the event only, the later extractors override the same keys.
+func WithExtractor(extractors ...Extractor) Option {
+	return func(o *options) {
+		o.extractors = append(o.extractors, extractors...)
+	}
+}
+
+func extract(ctx context.Context, extractors []Extractor) Map[string, any] {
+	var m Map[string, any]
+	for _, e := range extractors {
+		for k, v := range e(ctx) {
+			m = m.Set(k, v)
+		}
+	}
+	return m
+}
+
+// The keys of the data by `TraceParentExtractor`.
+const (
+	KeyTraceID = "trace_id"
+	KeySpanID  = "span_id"
+)
+
+// TraceParent is the W3C trace context, see https://www.w3.org/TR/trace-context/#traceparent-header.
+type TraceParent struct {
+	Version byte
+	TraceID [16]byte
+	SpanID  [8]byte
+	Flags   byte
+}
+
+func (t TraceParent) TraceIDString() string { return hex.EncodeToString(t.TraceID[:]) }
+func (t TraceParent) SpanIDString() string  { return hex.EncodeToString(t.SpanID[:]) }
+
+// Sampled reports whether the sampled flag is set.
+func (t TraceParent) Sampled() bool { return t.Flags&0x01 != 0 }
+
+// String returns the header value like "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01".
+func (t TraceParent) String() string {
+	return fmt.Sprintf("%02x-%s-%s-%02x", t.Version, t.TraceIDString(), t.SpanIDString(), t.Flags)
+}
+
+var ErrInvalidTraceParent = errors.New("InvalidTraceParent")
+
+// ParseTraceParent parses the value of the traceparent header.
+// The fields after the flags of the future versions are ignored.
+func ParseTraceParent(s string) (TraceParent, error) {
+	var (
+		t       TraceParent
+		b       [1]byte
+		invalid = func(reason string) (TraceParent, error) {
+			return TraceParent{}, fmt.Errorf("%w %q: %s", ErrInvalidTraceParent, s, reason)
+		}
+	)
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 {
+		return invalid("too few fields")
+	}
+	if !decodeHex(b[:], parts[0]) || b[0] == 0xff {
+		return invalid("invalid version")
+	}
+	t.Version = b[0]
+	if t.Version == 0 && len(parts) != 4 {
+		return invalid("too many fields")
+	}
+	if !decodeHex(t.TraceID[:], parts[1]) || t.TraceID == ([16]byte{}) {
+		return invalid("invalid trace-id")
+	}
+	if !decodeHex(t.SpanID[:], parts[2]) || t.SpanID == ([8]byte{}) {
+		return invalid("invalid parent-id")
+	}
+	if !decodeHex(b[:], parts[3]) {
+		return invalid("invalid trace-flags")
+	}
+	t.Flags = b[0]
+	return t, nil
+}
+
+// decodeHex decodes the lowercase hex of the exact length into dst.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != hex.EncodedLen(len(dst)) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+type traceParentKeyType struct{}
+
+var traceParentKey traceParentKeyType
+
+// WithTraceParent returns a new context with the value of the traceparent header, see `TraceParentExtractor`.
+func WithTraceParent(ctx context.Context, traceparent string) context.Context {
+	return context.WithValue(ctx, traceParentKey, traceparent)
+}
+
+// TraceParentExtractor extracts `KeyTraceID` and `KeySpanID` from the traceparent header value of ctx.
+// The value is set by `WithTraceParent`, or by the keys if given, the first found wins.
+// The values of the keys are string, []byte, `TraceParent` or fmt.Stringer.
+// Nothing is extracted if the value is not valid.
+func TraceParentExtractor(keys ...any) Extractor {
+	if len(keys) == 0 {
+		keys = []any{traceParentKey}
+	}
+	return func(ctx context.Context) Map[string, any] {
+		for _, k := range keys {
+			t, ok := traceParentOf(ctx.Value(k))
+			if !ok {
+				continue
+			}
+			return Map[string, any]{
+				KeyTraceID: t.TraceIDString(),
+				KeySpanID:  t.SpanIDString(),
+			}
+		}
+		return nil
+	}
+}
+
+func traceParentOf(v any) (TraceParent, bool) {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		return TraceParent{}, false
+	case TraceParent:
+		return x, true
+	case *TraceParent:
+		if x == nil {
+			return TraceParent{}, false
+		}
+		return *x, true
+	case string:
+		s = x
+	case []byte:
+		s = string(x)
+	case fmt.Stringer:
+		s = x.String()
+	default:
+		return TraceParent{}, false
+	}
+	t, err := ParseTraceParent(s)
+	return t, err == nil
+}
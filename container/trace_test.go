@@ -0,0 +1,140 @@
+package container_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/berquerant/logger/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTraceParent(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		value   string
+		want    string
+		sampled bool
+		err     bool
+	}{
+		{
+			title:   "sampled",
+			value:   "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			want:    "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			sampled: true,
+		},
+		{
+			title: "not sampled",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			want:  "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+		},
+		{
+			title:   "future version with more fields",
+			value:   "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			want:    "01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			sampled: true,
+		},
+		{
+			title: "version 00 with more fields",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			err:   true,
+		},
+		{
+			title: "invalid version",
+			value: "ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			err:   true,
+		},
+		{
+			title: "zero trace id",
+			value: "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			err:   true,
+		},
+		{
+			title: "zero span id",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			err:   true,
+		},
+		{
+			title: "uppercase",
+			value: "00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+			err:   true,
+		},
+		{
+			title: "short trace id",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e47-00f067aa0ba902b7-01",
+			err:   true,
+		},
+		{
+			title: "too few fields",
+			value: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			err:   true,
+		},
+		{
+			title: "empty",
+			err:   true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, err := container.ParseTraceParent(tc.value)
+			if tc.err {
+				assert.True(t, errors.Is(err, container.ErrInvalidTraceParent), "%v", err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got.String())
+			assert.Equal(t, tc.sampled, got.Sampled())
+		})
+	}
+}
+
+func TestTraceParentExtractor(t *testing.T) {
+	type headerKey struct{}
+	const value = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	want := container.Map[string, any]{
+		container.KeyTraceID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		container.KeySpanID:  "00f067aa0ba902b7",
+	}
+
+	t.Run("default key", func(t *testing.T) {
+		ctx := container.WithTraceParent(context.TODO(), value)
+		assert.Equal(t, want, container.TraceParentExtractor()(ctx))
+	})
+	t.Run("custom key", func(t *testing.T) {
+		ctx := context.WithValue(context.TODO(), headerKey{}, []byte(value))
+		assert.Equal(t, want, container.TraceParentExtractor(headerKey{})(ctx))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		ctx := container.WithTraceParent(context.TODO(), "00-invalid")
+		assert.Nil(t, container.TraceParentExtractor()(ctx))
+	})
+	t.Run("missing", func(t *testing.T) {
+		assert.Nil(t, container.TraceParentExtractor()(context.TODO()))
+	})
+}
+
+func TestExtractorSharedBase(t *testing.T) {
+	var got []string
+	base := container.New(map[string]any{
+		"app": "web",
+	}, logger.MustNewMapperFunc(func(ev logger.Event) {
+		got = append(got, fmt.Sprintf(ev.Format(), ev.Args()...))
+	}), container.WithExtractor(container.TraceParentExtractor()))
+
+	// request A has the traceparent
+	ctxA := base.WithContext(container.WithTraceParent(context.TODO(), "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"))
+	// request B has no traceparent
+	ctxB := base.WithContext(context.TODO())
+
+	container.FromContext(ctxA).L().Info("a")
+	container.FromContext(ctxB).L().Info("b")
+	base.L().Info("base")
+	assert.Equal(t, []string{
+		`a | {"app":"web","span_id":"00f067aa0ba902b7","trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"}`,
+		`b | {"app":"web"}`,
+		`base | {"app":"web"}`,
+	}, got)
+	assert.Equal(t, container.Map[string, any]{"app": "web"}, base.Snapshot())
+}
@@ -0,0 +1,39 @@
+package logger_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerContext(t *testing.T) {
+	type tenantKey struct{}
+	ctx := context.WithValue(context.TODO(), tenantKey{}, "t1")
+
+	var got []any
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(func(ev logger.Event) (logger.Event, error) {
+			got = append(got, logger.ContextOf(ev).Value(tenantKey{}))
+			return ev, nil
+		}),
+	}
+	l.InfoContext(ctx, "info")
+	l.WarnContext(ctx, "warn")
+	l.ErrorContext(ctx, "error")
+	l.DebugContext(ctx, "debug")
+	l.TraceContext(ctx, "trace")
+	l.Info("without context")
+	assert.Equal(t, []any{"t1", "t1", "t1", "t1", "t1", nil}, got)
+
+	t.Run("derived", func(t *testing.T) {
+		ev := logger.WithContext(logger.NewEvent(logger.Linfo, "msg", nil), ctx)
+		ev = logger.DeriveEvent(ev, logger.Lwarn, "changed", nil)
+		assert.Equal(t, "t1", logger.ContextOf(ev).Value(tenantKey{}))
+	})
+
+	t.Run("background", func(t *testing.T) {
+		assert.Equal(t, context.Background(), logger.ContextOf(logger.NewEvent(logger.Linfo, "msg", nil)))
+	})
+}
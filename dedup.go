@@ -0,0 +1,169 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DedupKeyFunc returns the key to compare the events in `Deduper`.
+type DedupKeyFunc func(Event) string
+
+// DedupKeyByMessage compares the formatted messages.
+func DedupKeyByMessage(ev Event) string { return fmt.Sprintf(ev.Format(), ev.Args()...) }
+
+// DedupKeyByFormatAndFields compares the formats and the values of the fields.
+func DedupKeyByFormatAndFields(keys ...string) DedupKeyFunc {
+	return func(ev Event) string {
+		var (
+			b      strings.Builder
+			fields = ExtraOf(ev).Fields
+		)
+		b.WriteString(ev.Format())
+		for _, k := range keys {
+			b.WriteString("\x00")
+			b.WriteString(k)
+			for _, f := range fields {
+				if f.Key == k {
+					fmt.Fprintf(&b, "=%v", f.Value)
+					break
+				}
+			}
+		}
+		return b.String()
+	}
+}
+
+// DeduperConfig is the configuration of `Deduper`.
+type DeduperConfig struct {
+	// Window is the maximum duration of a run of the repeated events.
+	// When the window expires, the summary is emitted and the next event is regarded as a new one.
+	// 0 means no limit.
+	Window time.Duration
+	// Key compares the events, default is `DedupKeyByMessage`.
+	Key DedupKeyFunc
+}
+
+// Deduper collapses the repeated events back to back like syslog.
+//
+// The first event of a run passes immediately and the repeated ones are dropped.
+// The summary like "last message repeated 57 times" is passed to the emit function, see `SetEmit`,
+// when the run ends by a different event, when the window expires, or by Flush.
+// In the pipeline built by `Build`, emit passes the summaries to the following stages.
+type Deduper struct {
+	cfg DeduperConfig
+
+	mux      sync.Mutex
+	last     Event
+	lastKey  string
+	repeated int
+	// run identifies the current run to ignore the stale timers
+	run   uint64
+	timer *time.Timer
+	emit  func(Event)
+}
+
+func NewDeduper(cfg DeduperConfig) *Deduper {
+	if cfg.Key == nil {
+		cfg.Key = DedupKeyByMessage
+	}
+	return &Deduper{
+		cfg: cfg,
+	}
+}
+
+// SetEmit sets the destination of the summaries.
+func (d *Deduper) SetEmit(emit func(Event)) {
+	d.mux.Lock()
+	defer d.mux.Unlock()
+	d.emit = emit
+}
+
+// Mapper passes the event if it differs from the last one, otherwise returns nil.
+func (d *Deduper) Mapper(ev Event) (Event, error) {
+	key := d.cfg.Key(ev)
+	d.mux.Lock()
+	if d.last != nil && key == d.lastKey {
+		d.repeated++
+		d.mux.Unlock()
+		return nil, nil
+	}
+	summary := d.endRun()
+	d.last = ev
+	d.lastKey = key
+	if d.cfg.Window > 0 {
+		run := d.run
+		d.timer = time.AfterFunc(d.cfg.Window, func() { d.expire(run) })
+	}
+	emit := d.emit
+	d.mux.Unlock()
+
+	d.send(emit, summary)
+	return ev, nil
+}
+
+func (d *Deduper) send(emit func(Event), summary Event) {
+	if emit != nil && summary != nil {
+		emit(summary)
+	}
+}
+
+// endRun stops the current run and returns the summary, nil if no repeats.
+func (d *Deduper) endRun() Event {
+	var summary Event
+	if d.last != nil && d.repeated > 0 {
+		extra := ExtraOf(d.last)
+		summary = WithExtra(
+			NewEvent(d.last.Level(), "last message repeated %d times", []any{d.repeated}),
+			Extra{
+				Time: time.Now(),
+				Name: extra.Name,
+				Fields: []Field{
+					{Key: "repeated", Value: d.repeated},
+				},
+			},
+		)
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+		d.timer = nil
+	}
+	d.last = nil
+	d.lastKey = ""
+	d.repeated = 0
+	d.run++
+	return summary
+}
+
+func (d *Deduper) expire(run uint64) {
+	d.mux.Lock()
+	if run != d.run {
+		d.mux.Unlock()
+		return
+	}
+	summary := d.endRun()
+	emit := d.emit
+	d.mux.Unlock()
+	d.send(emit, summary)
+}
+
+// Flush ends the current run and emits the summary.
+func (d *Deduper) Flush() error {
+	d.mux.Lock()
+	summary := d.endRun()
+	emit := d.emit
+	d.mux.Unlock()
+	d.send(emit, summary)
+	return nil
+}
+
+// Stage returns the stage of the pipeline.
+func (d *Deduper) Stage() *Stage {
+	return &Stage{
+		Mapper:  d.Mapper,
+		Flush:   d.Flush,
+		Close:   d.Flush,
+		Connect: d.SetEmit,
+	}
+}
@@ -0,0 +1,82 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeduper(t *testing.T) {
+	var got []string
+	consume := func(ev logger.Event) { got = append(got, fmt.Sprint(ev)) }
+	d := logger.NewDeduper(logger.DeduperConfig{})
+	d.SetEmit(consume)
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.MustNewMapperFunc(d.Mapper).Next(consume)),
+	}
+
+	for i := 0; i < 3; i++ {
+		l.Info("connecting to %s", "db")
+	}
+	l.Info("connected")
+	l.Warn("slow")
+	l.Warn("slow")
+	assert.Nil(t, d.Flush())
+	l.Warn("slow")
+
+	assert.Equal(t, []string{
+		"connecting to db",
+		"last message repeated 2 times",
+		"connected",
+		"slow",
+		"last message repeated 1 times",
+		"slow",
+	}, got)
+}
+
+func TestDeduperFields(t *testing.T) {
+	d := logger.NewDeduper(logger.DeduperConfig{
+		Key: logger.DedupKeyByFormatAndFields("host"),
+	})
+	newEv := func(n int, host string) logger.Event {
+		return logger.AddFields(logger.NewEvent(logger.Lerror, "retry %d", []any{n}), logger.Field{Key: "host", Value: host})
+	}
+	var passed int
+	for _, ev := range []logger.Event{
+		newEv(1, "a"),
+		newEv(2, "a"),
+		newEv(3, "b"),
+	} {
+		if got, _ := d.Mapper(ev); got != nil {
+			passed++
+		}
+	}
+	assert.Equal(t, 2, passed)
+}
+
+func TestDeduperWindow(t *testing.T) {
+	var (
+		d      = logger.NewDeduper(logger.DeduperConfig{Window: 20 * time.Millisecond})
+		ev     = logger.NewEvent(logger.Linfo, "tick", nil)
+		result = make(chan logger.Event, 1)
+	)
+	d.SetEmit(func(ev logger.Event) { result <- ev })
+
+	got, _ := d.Mapper(ev)
+	assert.NotNil(t, got)
+	got, _ = d.Mapper(ev)
+	assert.Nil(t, got)
+
+	select {
+	case summary := <-result:
+		assert.Equal(t, "last message repeated 1 times", fmt.Sprint(summary))
+		assert.Equal(t, logger.Linfo, summary.Level())
+	case <-time.After(time.Second):
+		t.Fatal("no summary")
+	}
+	got, _ = d.Mapper(ev)
+	assert.NotNil(t, got, "new run after the window")
+}
@@ -0,0 +1,413 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Format is the name of the output format.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatJSON   Format = "json"
+	FormatLogfmt Format = "logfmt"
+)
+
+var ErrInvalidFormat = errors.New("InvalidFormat")
+
+// ParseFormat converts a string into a format.
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatText, FormatJSON, FormatLogfmt:
+		return f, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrInvalidFormat, s)
+	}
+}
+
+// Keys of the structured formats.
+const (
+	KeyTime   = "time"
+	KeyLevel  = "level"
+	KeyName   = "name"
+	KeyMsg    = "msg"
+	KeyCaller = "caller"
+	KeyError  = "error"
+	KeyStack  = "stack"
+
+	keyErrorType   = KeyError + "_type"
+	keyErrorCauses = KeyError + "_causes"
+)
+
+// isReservedKey reports whether the key is written by the structured formats other than the fields.
+func isReservedKey(key string) bool {
+	switch key {
+	case KeyTime, KeyLevel, KeyName, KeyMsg, KeyCaller, KeyError, KeyStack, keyErrorType, keyErrorCauses:
+		return true
+	default:
+		return false
+	}
+}
+
+// Encoder converts an event into a line, without the trailing newline.
+type Encoder func(ev Event) ([]byte, error)
+
+// EncoderConfig is the configuration common to the encoders.
+type EncoderConfig struct {
+	// TimeFormat is the layout of the time, see `time.Layout`.
+	// Omit the time if empty.
+	TimeFormat string
+	// Caller enables to write the call site of the event if recorded.
+	Caller bool
+	// Theme colors the text format, no colors if nil.
+	// See `AutoTheme` to disable the colors when the destination is not a terminal.
+	Theme *Theme
+	// NoSanitize disables escaping the control characters in the text formats, see `Sanitize`.
+	// The structured formats always escape them by quoting.
+	NoSanitize bool
+	// Fields is how to write the fields, default is FieldsInline.
+	Fields FieldsStyle
+	// FieldsKey is the key of FieldsJSON in JSON and logfmt, default is `KeyFields`.
+	FieldsKey string
+}
+
+// sanitize escapes s unless disabled.
+func (c EncoderConfig) sanitize(s string) string {
+	if c.NoSanitize {
+		return s
+	}
+	return Sanitize(s)
+}
+
+// sanitizeMessage escapes the message or the name unless already escaped by `SanitizeMapper`.
+func (c EncoderConfig) sanitizeMessage(extra Extra, s string) string {
+	if extra.Sanitized {
+		return s
+	}
+	return c.sanitize(s)
+}
+
+func (c EncoderConfig) time(ev Event) (string, bool) {
+	t := ExtraOf(ev).Time
+	if c.TimeFormat == "" || t.IsZero() {
+		return "", false
+	}
+	return t.Format(c.TimeFormat), true
+}
+
+func (c EncoderConfig) caller(ev Event) (string, bool) {
+	if !c.Caller {
+		return "", false
+	}
+	f, ok := ExtraOf(ev).Frame()
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("%s:%d", f.File, f.Line), true
+}
+
+// NewEncoder returns the encoder of the format.
+func NewEncoder(format Format, cfg EncoderConfig) (Encoder, error) {
+	switch format {
+	case FormatText:
+		return NewTextEncoder(cfg), nil
+	case FormatJSON:
+		return NewJSONEncoder(cfg), nil
+	case FormatLogfmt:
+		return NewLogfmtEncoder(cfg), nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrInvalidFormat, format)
+	}
+}
+
+// NewTextEncoder returns an encoder that writes like `2022/09/20 10:00:00 I | [name] message caller=main.go:10 key=value`.
+// Colored by `EncoderConfig.Theme` if set.
+// If the attached error wraps others, the unwrap tree follows in the indented lines, see `Extra.Err`,
+// and then the stack trace, see `Extra.Stack`.
+// The message, the name and the keys are escaped by `Sanitize` unless `EncoderConfig.NoSanitize`,
+// so that an event is always a line.
+// The fields are written by `EncoderConfig.Fields`.
+func NewTextEncoder(cfg EncoderConfig) Encoder {
+	var (
+		theme       = cfg.Theme
+		timeColor   = theme.get(func(t *Theme) string { return t.Time })
+		nameColor   = theme.get(func(t *Theme) string { return t.Name })
+		keyColor    = theme.get(func(t *Theme) string { return t.Key })
+		callerColor = theme.get(func(t *Theme) string { return t.Caller })
+	)
+	return func(ev Event) ([]byte, error) {
+		var b bytes.Buffer
+		if t, ok := cfg.time(ev); ok {
+			paint(&b, timeColor, t)
+			b.WriteByte(' ')
+		}
+		paint(&b, theme.level(ev.Level()), logLevelToPrefix(ev.Level()))
+		b.WriteByte(' ')
+		extra := ExtraOf(ev)
+		if extra.Name != "" {
+			paint(&b, nameColor, "["+cfg.sanitizeMessage(extra, extra.Name)+"]")
+			b.WriteByte(' ')
+		}
+		b.WriteString(cfg.sanitizeMessage(extra, fmt.Sprintf(ev.Format(), ev.Args()...)))
+		if c, ok := cfg.caller(ev); ok {
+			b.WriteByte(' ')
+			paint(&b, callerColor, KeyCaller+"="+c)
+		}
+		switch cfg.fieldsStyle() {
+		case FieldsInline:
+			for _, f := range extra.Fields {
+				b.WriteByte(' ')
+				paint(&b, keyColor, cfg.sanitize(f.Key)+"=")
+				b.WriteString(logfmtValue(fieldString(f.Value)))
+			}
+		case FieldsJSON:
+			if len(extra.Fields) > 0 {
+				j, err := fieldsJSON(extra.Fields)
+				if err != nil {
+					return nil, err
+				}
+				b.WriteString(" | ")
+				b.Write(j)
+			}
+		}
+		if extra.Err != nil {
+			if n := newErrorNode(extra.Err, 0); len(n.Causes) > 0 {
+				writeErrorTree(&b, n, cfg, 0)
+			}
+		}
+		writeStack(&b, extra.Stack)
+		return b.Bytes(), nil
+	}
+}
+
+// NewJSONEncoder returns an encoder that writes like `{"time":"2022-09-20T10:00:00Z","level":"info","msg":"message"}`.
+// The attached error is written as the unwrap tree like `"error":{"msg":"...","type":"...","causes":[...]}`,
+// the stack trace like `"stack":[{"func":"main.main","file":"/src/main.go","line":10}]`.
+func NewJSONEncoder(cfg EncoderConfig) Encoder {
+	return func(ev Event) ([]byte, error) {
+		var (
+			b     bytes.Buffer
+			first = true
+		)
+		add := func(key string, value any) error {
+			v, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+			if !first {
+				b.WriteByte(',')
+			}
+			first = false
+			k, _ := json.Marshal(key)
+			b.Write(k)
+			b.WriteByte(':')
+			b.Write(v)
+			return nil
+		}
+
+		b.WriteByte('{')
+		if t, ok := cfg.time(ev); ok {
+			_ = add(KeyTime, t)
+		}
+		_ = add(KeyLevel, ev.Level().String())
+		extra := ExtraOf(ev)
+		if extra.Name != "" {
+			_ = add(KeyName, extra.Name)
+		}
+		_ = add(KeyMsg, fmt.Sprintf(ev.Format(), ev.Args()...))
+		if c, ok := cfg.caller(ev); ok {
+			_ = add(KeyCaller, c)
+		}
+		if extra.Err != nil {
+			_ = add(KeyError, newErrorNode(extra.Err, 0))
+		}
+		if len(extra.Stack) > 0 {
+			_ = add(KeyStack, stackJSON(extra.Stack))
+		}
+		switch cfg.fieldsStyle() {
+		case FieldsInline:
+			for _, f := range extra.Fields {
+				if err := add(cfg.inlineKey(f.Key), f.Value); err != nil {
+					return nil, err
+				}
+			}
+		case FieldsJSON:
+			if len(extra.Fields) > 0 {
+				j, err := fieldsJSON(extra.Fields)
+				if err != nil {
+					return nil, err
+				}
+				_ = add(cfg.fieldsKey(), json.RawMessage(j))
+			}
+		}
+		b.WriteByte('}')
+		return b.Bytes(), nil
+	}
+}
+
+// NewLogfmtEncoder returns an encoder that writes like `time=2022-09-20T10:00:00Z level=info msg="a message"`.
+// The attached error is written as error, error_type and error_causes, the causes are the unwrap tree in JSON.
+// The stack trace is written as stack in JSON.
+func NewLogfmtEncoder(cfg EncoderConfig) Encoder {
+	return func(ev Event) ([]byte, error) {
+		var b bytes.Buffer
+		add := func(key, value string) {
+			if b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			b.WriteString(cfg.sanitize(key))
+			b.WriteByte('=')
+			b.WriteString(logfmtValue(value))
+		}
+
+		if t, ok := cfg.time(ev); ok {
+			add(KeyTime, t)
+		}
+		add(KeyLevel, ev.Level().String())
+		extra := ExtraOf(ev)
+		if extra.Name != "" {
+			add(KeyName, extra.Name)
+		}
+		add(KeyMsg, fmt.Sprintf(ev.Format(), ev.Args()...))
+		if c, ok := cfg.caller(ev); ok {
+			add(KeyCaller, c)
+		}
+		if extra.Err != nil {
+			n := newErrorNode(extra.Err, 0)
+			add(KeyError, n.Msg)
+			add(keyErrorType, n.Type)
+			if len(n.Causes) > 0 {
+				add(keyErrorCauses, fieldString(n.Causes))
+			}
+		}
+		if len(extra.Stack) > 0 {
+			add(KeyStack, fieldString(stackJSON(extra.Stack)))
+		}
+		switch cfg.fieldsStyle() {
+		case FieldsInline:
+			for _, f := range extra.Fields {
+				add(cfg.inlineKey(f.Key), fieldString(f.Value))
+			}
+		case FieldsJSON:
+			if len(extra.Fields) > 0 {
+				j, err := fieldsJSON(extra.Fields)
+				if err != nil {
+					return nil, err
+				}
+				add(cfg.fieldsKey(), string(j))
+			}
+		}
+		return b.Bytes(), nil
+	}
+}
+
+// fieldString converts a field value into a string for the text formats.
+func fieldString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	switch reflect.ValueOf(v).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct, reflect.Pointer:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprint(v)
+}
+
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == '\\' || !unicode.IsPrint(r) {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+// RawEncoder writes the formatted message only.
+func RawEncoder(ev Event) ([]byte, error) {
+	return []byte(fmt.Sprintf(ev.Format(), ev.Args()...)), nil
+}
+
+// NewEncodeMapper returns a MapperFunc that replaces the message of an event with the encoded one.
+// Use `RawEncoder` to write the returned events.
+func NewEncodeMapper(enc Encoder) MapperFunc {
+	return func(ev Event) (Event, error) {
+		b, err := enc(ev)
+		if err != nil {
+			return nil, err
+		}
+		return DeriveEvent(ev, ev.Level(), "%s", []any{string(b)}), nil
+	}
+}
+
+// NewWriterConsumer returns a MapperFunc that writes an encoded event and a newline to w.
+// Writes are serialized.
+// The events whose context is already done are not written, the error of the context is returned, see `ContextOf`.
+func NewWriterConsumer(w io.Writer, enc Encoder) MapperFunc {
+	var mux sync.Mutex
+	return func(ev Event) (Event, error) {
+		if err := ContextOf(ev).Err(); err != nil {
+			return nil, err
+		}
+		b, err := enc(ev)
+		if err != nil {
+			return nil, err
+		}
+		b = append(b, '\n')
+		mux.Lock()
+		defer mux.Unlock()
+		if _, err := w.Write(b); err != nil {
+			return nil, err
+		}
+		return ev, nil
+	}
+}
+
+// Named time formats available in the configurations.
+var timeFormats = map[string]string{
+	"ansic":       time.ANSIC,
+	"rfc822":      time.RFC822,
+	"rfc1123":     time.RFC1123,
+	"rfc3339":     time.RFC3339,
+	"rfc3339nano": time.RFC3339Nano,
+	"kitchen":     time.Kitchen,
+	"stamp":       time.Stamp,
+	"stampmilli":  time.StampMilli,
+	"stampmicro":  time.StampMicro,
+	"datetime":    "2006-01-02 15:04:05",
+	"log":         "2006/01/02 15:04:05",
+}
+
+// ParseTimeFormat converts a name of the time format like "RFC3339" into the layout.
+// Other strings are regarded as layouts, "none" means no time.
+func ParseTimeFormat(s string) string {
+	name := strings.ToLower(strings.TrimSpace(s))
+	if name == "none" {
+		return ""
+	}
+	if layout, ok := timeFormats[name]; ok {
+		return layout
+	}
+	return s
+}
@@ -0,0 +1,101 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoder(t *testing.T) {
+	ev := logger.WithExtra(
+		logger.NewEvent(logger.Lwarn, "disk %s", []any{"is full"}),
+		logger.Extra{
+			Time: time.Date(2022, 9, 20, 10, 0, 0, 0, time.UTC),
+		},
+	)
+	cfg := logger.EncoderConfig{
+		TimeFormat: time.RFC3339,
+	}
+
+	for _, tc := range []struct {
+		title  string
+		format logger.Format
+		cfg    logger.EncoderConfig
+		want   string
+	}{
+		{
+			title:  "text",
+			format: logger.FormatText,
+			cfg:    cfg,
+			want:   `2022-09-20T10:00:00Z W | disk is full`,
+		},
+		{
+			title:  "text without time",
+			format: logger.FormatText,
+			want:   `W | disk is full`,
+		},
+		{
+			title:  "json",
+			format: logger.FormatJSON,
+			cfg:    cfg,
+			want:   `{"time":"2022-09-20T10:00:00Z","level":"warn","msg":"disk is full"}`,
+		},
+		{
+			title:  "logfmt",
+			format: logger.FormatLogfmt,
+			cfg:    cfg,
+			want:   `time=2022-09-20T10:00:00Z level=warn msg="disk is full"`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			enc, err := logger.NewEncoder(tc.format, tc.cfg)
+			assert.Nil(t, err)
+			got, err := enc(ev)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestWriterConsumerCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.NewWriterConsumer(&buf, logger.NewLogfmtEncoder(logger.EncoderConfig{
+			Caller: true,
+		}))),
+		Caller: true,
+	}
+	l.Info("here")
+	got := buf.String()
+	assert.True(t, strings.HasPrefix(got, "level=info msg=here caller="), got)
+	assert.Contains(t, got, "encoder_test.go:")
+	assert.True(t, strings.HasSuffix(got, "\n"))
+}
+
+func TestWriterConsumerContextDone(t *testing.T) {
+	var (
+		buf  bytes.Buffer
+		errs []error
+	)
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.NewWriterConsumer(&buf, logger.NewLogfmtEncoder(logger.EncoderConfig{}))),
+	}
+	l.SetErrConsumer(func(err error) {
+		errs = append(errs, err)
+	})
+	ctx, cancel := context.WithCancel(context.TODO())
+	l.InfoContext(ctx, "before")
+	cancel()
+	l.InfoContext(ctx, "after")
+	l.Info("without context")
+	assert.Equal(t, "level=info msg=before\nlevel=info msg=\"without context\"\n", buf.String())
+	if assert.Equal(t, 1, len(errs)) {
+		assert.ErrorIs(t, errs[0], context.Canceled)
+	}
+}
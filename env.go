@@ -0,0 +1,179 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables to configure the loggers.
+const (
+	// EnvLevel is the level, like "info" or "30".
+	EnvLevel = "LOG_LEVEL"
+	// EnvFormat is the output format: text, json or logfmt.
+	EnvFormat = "LOG_FORMAT"
+	// EnvOutput is the destination: stderr, stdout or a file path.
+	EnvOutput = "LOG_OUTPUT"
+	// EnvTimeFormat is the time layout or the name of the layout like "RFC3339", "none" disables the time.
+	EnvTimeFormat = "LOG_TIME_FORMAT"
+	// EnvCaller enables to write the call site, like "true".
+	EnvCaller = "LOG_CALLER"
+	// EnvColor is when to color the text format: auto, always or never.
+	EnvColor = "LOG_COLOR"
+)
+
+const (
+	OutputStderr = "stderr"
+	OutputStdout = "stdout"
+)
+
+var ErrInvalidEnv = errors.New("InvalidEnv")
+
+// EnvConfig is the logger configuration from the environment variables.
+type EnvConfig struct {
+	Level Level
+	// Format is the output format.
+	// Empty means the standard one, `LogLevelToPrefixMapper` and `StandardLogConsumer`.
+	Format Format
+	// Output is the destination.
+	Output     string
+	TimeFormat string
+	Caller     bool
+	// Color is when to color the text format.
+	Color ColorMode
+}
+
+// ParseEnvConfig reads the configuration by lookup, like `os.LookupEnv`.
+// level is used when the level variable is not set.
+// Invalid values are ignored and returned as errors.
+func ParseEnvConfig(level Level, lookup func(string) (string, bool)) (*EnvConfig, []error) {
+	var (
+		c = &EnvConfig{
+			Level:  level,
+			Output: OutputStderr,
+			Color:  ColorAuto,
+		}
+		errs    []error
+		invalid = func(key, value string, err error) {
+			errs = append(errs, fmt.Errorf("%w %s=%q: %v", ErrInvalidEnv, key, value, err))
+		}
+		structured bool
+	)
+
+	if v, ok := lookup(EnvLevel); ok {
+		if x, err := ParseLevel(v); err != nil {
+			invalid(EnvLevel, v, err)
+		} else {
+			c.Level = x
+		}
+	}
+	if v, ok := lookup(EnvFormat); ok {
+		if x, err := ParseFormat(v); err != nil {
+			invalid(EnvFormat, v, err)
+		} else {
+			c.Format = x
+		}
+	}
+	if v, ok := lookup(EnvOutput); ok {
+		if strings.TrimSpace(v) == "" {
+			invalid(EnvOutput, v, errors.New("empty"))
+		} else {
+			c.Output = v
+			// the standard log writes to stderr
+			structured = !strings.EqualFold(strings.TrimSpace(v), OutputStderr)
+		}
+	}
+	if v, ok := lookup(EnvCaller); ok {
+		if x, err := strconv.ParseBool(v); err != nil {
+			invalid(EnvCaller, v, err)
+		} else {
+			c.Caller = x
+			structured = structured || x
+		}
+	}
+	if v, ok := lookup(EnvColor); ok {
+		if x, err := ParseColorMode(v); err != nil {
+			invalid(EnvColor, v, err)
+		} else {
+			c.Color = x
+		}
+	}
+	v, timeFormatOK := lookup(EnvTimeFormat)
+	if timeFormatOK {
+		c.TimeFormat = ParseTimeFormat(v)
+		structured = true
+	}
+
+	if c.Format == "" && structured {
+		// the standard log cannot write the configured things
+		c.Format = FormatText
+	}
+	if !timeFormatOK {
+		switch c.Format {
+		case FormatText:
+			c.TimeFormat = timeFormats["log"]
+		case FormatJSON, FormatLogfmt:
+			c.TimeFormat = time.RFC3339Nano
+		}
+	}
+	return c, errs
+}
+
+// openOutput opens the destination: stderr, stdout or a file path.
+// The returned closer is nil for stderr and stdout.
+func openOutput(output string) (io.Writer, io.Closer, error) {
+	switch strings.ToLower(output) {
+	case "", OutputStderr:
+		return os.Stderr, nil, nil
+	case OutputStdout:
+		return os.Stdout, nil, nil
+	default:
+		f, err := os.OpenFile(output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+		return f, f, nil
+	}
+}
+
+// Consumer returns a MapperFunc that formats and writes events,
+// and the closer of the output file, nil for stderr and stdout.
+// If it fails to open the output, the returned MapperFunc writes to stderr.
+func (c *EnvConfig) Consumer() (MapperFunc, io.Closer, error) {
+	if c.Format == "" {
+		return standardLogMapper, nil, nil
+	}
+	var (
+		w, closer, openErr = openOutput(c.Output)
+		cfg                = EncoderConfig{
+			TimeFormat: c.TimeFormat,
+			Caller:     c.Caller,
+		}
+	)
+	if openErr != nil {
+		w = os.Stderr
+		openErr = fmt.Errorf("%w %s=%q: %v", ErrInvalidEnv, EnvOutput, c.Output, openErr)
+	}
+	cfg.Theme = c.Color.Theme(w, DefaultTheme)
+	enc, err := NewEncoder(c.Format, cfg)
+	if err != nil {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, nil, err
+	}
+	return NewWriterConsumer(w, enc), closer, openErr
+}
+
+func newEnvConsumer(level Level) (*EnvConfig, MapperFunc, io.Closer, []error) {
+	c, errs := ParseEnvConfig(level, os.LookupEnv)
+	consumer, closer, err := c.Consumer()
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return c, consumer, closer, errs
+}
@@ -0,0 +1,146 @@
+package logger_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEnvConfig(t *testing.T) {
+	for _, tc := range []struct {
+		title   string
+		env     map[string]string
+		want    *logger.EnvConfig
+		errKeys []string
+	}{
+		{
+			title: "no variables",
+			env:   map[string]string{},
+			want: &logger.EnvConfig{
+				Level:  logger.Lwarn,
+				Output: logger.OutputStderr,
+				Color:  logger.ColorAuto,
+			},
+		},
+		{
+			title: "json",
+			env: map[string]string{
+				logger.EnvLevel:  "debug",
+				logger.EnvFormat: "JSON",
+			},
+			want: &logger.EnvConfig{
+				Level:      logger.Ldebug,
+				Format:     logger.FormatJSON,
+				Output:     logger.OutputStderr,
+				TimeFormat: time.RFC3339Nano,
+				Color:      logger.ColorAuto,
+			},
+		},
+		{
+			title: "text by output",
+			env: map[string]string{
+				logger.EnvOutput:     "stdout",
+				logger.EnvTimeFormat: "none",
+				logger.EnvCaller:     "true",
+			},
+			want: &logger.EnvConfig{
+				Level:  logger.Lwarn,
+				Format: logger.FormatText,
+				Output: logger.OutputStdout,
+				Caller: true,
+				Color:  logger.ColorAuto,
+			},
+		},
+		{
+			title: "default output",
+			env: map[string]string{
+				logger.EnvOutput: "stderr",
+			},
+			want: &logger.EnvConfig{
+				Level:  logger.Lwarn,
+				Output: "stderr",
+				Color:  logger.ColorAuto,
+			},
+		},
+		{
+			title: "named time format",
+			env: map[string]string{
+				logger.EnvFormat:     "logfmt",
+				logger.EnvTimeFormat: "rfc3339",
+				logger.EnvColor:      "never",
+			},
+			want: &logger.EnvConfig{
+				Level:      logger.Lwarn,
+				Format:     logger.FormatLogfmt,
+				Output:     logger.OutputStderr,
+				TimeFormat: time.RFC3339,
+				Color:      logger.ColorNever,
+			},
+		},
+		{
+			title: "invalid values",
+			env: map[string]string{
+				logger.EnvLevel:  "loud",
+				logger.EnvFormat: "xml",
+				logger.EnvCaller: "maybe",
+			},
+			want: &logger.EnvConfig{
+				Level:  logger.Lwarn,
+				Output: logger.OutputStderr,
+				Color:  logger.ColorAuto,
+			},
+			errKeys: []string{logger.EnvLevel, logger.EnvFormat, logger.EnvCaller},
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got, errs := logger.ParseEnvConfig(logger.Lwarn, func(key string) (string, bool) {
+				v, ok := tc.env[key]
+				return v, ok
+			})
+			assert.Equal(t, tc.want, got)
+			if assert.Equal(t, len(tc.errKeys), len(errs)) {
+				for i, err := range errs {
+					assert.ErrorIs(t, err, logger.ErrInvalidEnv)
+					assert.Contains(t, err.Error(), tc.errKeys[i])
+				}
+			}
+		})
+	}
+}
+
+func TestNewDefaultFromEnv(t *testing.T) {
+	t.Setenv(logger.EnvLevel, "error")
+	t.Setenv(logger.EnvFormat, "json")
+	t.Setenv(logger.EnvTimeFormat, "none")
+	t.Setenv(logger.EnvOutput, t.TempDir()) // directory cannot be opened as a file
+
+	var errs []error
+	_ = logger.NewDefaultFromEnv(logger.Linfo, func(err error) {
+		errs = append(errs, err)
+	})
+	if assert.Equal(t, 1, len(errs)) {
+		assert.ErrorIs(t, errs[0], logger.ErrInvalidEnv)
+		assert.Contains(t, errs[0].Error(), logger.EnvOutput)
+	}
+}
+
+func TestNewDefaultFromEnvClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.log")
+	t.Setenv(logger.EnvFormat, "logfmt")
+	t.Setenv(logger.EnvTimeFormat, "none")
+	t.Setenv(logger.EnvOutput, path)
+
+	l := logger.NewDefaultFromEnv(logger.Linfo, nil)
+	l.Info("written")
+	assert.Nil(t, l.Close())
+	l.Info("closed")
+
+	b, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "level=info msg=written\n", string(b))
+}
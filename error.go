@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// WithError returns a new event with the error attached, see `Extra.Err`.
+func WithError(ev Event, err error) Event {
+	extra := ExtraOf(ev)
+	extra.Err = err
+	return WithExtra(ev, extra)
+}
+
+// ErrorOf returns the error attached to the event, nil if not attached.
+func ErrorOf(ev Event) error { return ExtraOf(ev).Err }
+
+// firstError returns the first error of the args.
+func firstError(args []any) error {
+	for _, a := range args {
+		if err, ok := a.(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// unwrapErrors returns the errors wrapped by err, by `Unwrap() error` or `Unwrap() []error`.
+func unwrapErrors(err error) []error {
+	switch x := err.(type) {
+	case interface{ Unwrap() []error }:
+		return x.Unwrap()
+	case interface{ Unwrap() error }:
+		if e := x.Unwrap(); e != nil {
+			return []error{e}
+		}
+	}
+	return nil
+}
+
+// errorMaxDepth limits the unwrap chain, against the cyclic errors.
+const errorMaxDepth = 16
+
+// errorNode is the unwrap tree of an error.
+type errorNode struct {
+	Msg    string       `json:"msg"`
+	Type   string       `json:"type"`
+	Causes []*errorNode `json:"causes,omitempty"`
+}
+
+func newErrorNode(err error, depth int) *errorNode {
+	n := &errorNode{
+		Msg:  err.Error(),
+		Type: errorType(err),
+	}
+	if depth >= errorMaxDepth {
+		return n
+	}
+	for _, e := range unwrapErrors(err) {
+		if e != nil {
+			n.Causes = append(n.Causes, newErrorNode(e, depth+1))
+		}
+	}
+	return n
+}
+
+func errorType(err error) string {
+	if x, ok := err.(*redactedError); ok {
+		return errorType(x.err)
+	}
+	return fmt.Sprintf("%T", err)
+}
+
+// writeErrorTree writes the tree like
+//
+//	error: open config: no such file or directory (*fmt.wrapError)
+//	    cause: no such file or directory (*fs.PathError)
+//
+// each node is a line that starts with the indent.
+func writeErrorTree(b *bytes.Buffer, n *errorNode, cfg EncoderConfig, depth int) {
+	b.WriteByte('\n')
+	b.WriteString(strings.Repeat("    ", depth+1))
+	if depth == 0 {
+		b.WriteString("error: ")
+	} else {
+		b.WriteString("cause: ")
+	}
+	fmt.Fprintf(b, "%s (%s)", cfg.sanitize(n.Msg), n.Type)
+	for _, c := range n.Causes {
+		writeErrorTree(b, c, cfg, depth+1)
+	}
+}
+
+// ErrorMatcher reports whether the error matches, see `ErrorFilter` and `ErrorRouter`.
+type ErrorMatcher func(err error) bool
+
+// ErrorIs matches the errors by `errors.Is`.
+func ErrorIs(target error) ErrorMatcher {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+// ErrorAs matches the errors by `errors.As` with the type T.
+func ErrorAs[T error]() ErrorMatcher {
+	return func(err error) bool {
+		var x T
+		return errors.As(err, &x)
+	}
+}
+
+// ErrorFilter passes the events with the error attached and matched, ignores others.
+func ErrorFilter(match ErrorMatcher) MapperFunc {
+	return func(ev Event) (Event, error) {
+		if err := ErrorOf(ev); err != nil && match(err) {
+			return ev, nil
+		}
+		return nil, nil
+	}
+}
+
+// ErrorRouter passes the events with the error attached and matched to the mapper
+// instead of the following mappers, passes others as they are.
+func ErrorRouter(match ErrorMatcher, mapper MapperFunc) MapperFunc {
+	return func(ev Event) (Event, error) {
+		if err := ErrorOf(ev); err != nil && match(err) {
+			if _, err := mapper.Call(ev); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		}
+		return ev, nil
+	}
+}
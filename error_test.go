@@ -0,0 +1,124 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type joinError []error
+
+func (e joinError) Error() string {
+	ss := make([]string, len(e))
+	for i, x := range e {
+		ss[i] = x.Error()
+	}
+	return strings.Join(ss, "; ")
+}
+
+func (e joinError) Unwrap() []error { return e }
+
+var errTimeout = errors.New("timeout")
+
+func newTestError() error {
+	return fmt.Errorf("save: %w", joinError{
+		&fs.PathError{Op: "open", Path: "a.txt", Err: fs.ErrNotExist},
+		errTimeout,
+	})
+}
+
+func TestLoggerAttachesError(t *testing.T) {
+	var got logger.Event
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.MustNewMapperFunc(func(ev logger.Event) { got = ev })),
+	}
+	err := newTestError()
+	l.Error("failed %d: %v", 1, err)
+	assert.Equal(t, err, logger.ErrorOf(got))
+	l.Info("ok")
+	assert.Nil(t, logger.ErrorOf(got))
+}
+
+func TestEncodeError(t *testing.T) {
+	ev := logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), newTestError())
+
+	t.Run("json", func(t *testing.T) {
+		b, err := logger.NewJSONEncoder(logger.EncoderConfig{})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"level":"error","msg":"failed","error":{"msg":"save: open a.txt: file does not exist; timeout","type":"*fmt.wrapError","causes":[`+
+			`{"msg":"open a.txt: file does not exist; timeout","type":"logger_test.joinError","causes":[`+
+			`{"msg":"open a.txt: file does not exist","type":"*fs.PathError","causes":[{"msg":"file does not exist","type":"*errors.errorString"}]},`+
+			`{"msg":"timeout","type":"*errors.errorString"}]}]}}`, string(b))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		b, err := logger.NewTextEncoder(logger.EncoderConfig{})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `E | failed
+    error: save: open a.txt: file does not exist; timeout (*fmt.wrapError)
+        cause: open a.txt: file does not exist; timeout (logger_test.joinError)
+            cause: open a.txt: file does not exist (*fs.PathError)
+                cause: file does not exist (*errors.errorString)
+            cause: timeout (*errors.errorString)`, string(b))
+	})
+
+	t.Run("text without causes", func(t *testing.T) {
+		b, err := logger.NewTextEncoder(logger.EncoderConfig{})(logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), errTimeout))
+		assert.Nil(t, err)
+		assert.Equal(t, `E | failed`, string(b))
+	})
+
+	t.Run("logfmt", func(t *testing.T) {
+		b, err := logger.NewLogfmtEncoder(logger.EncoderConfig{})(logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), errTimeout))
+		assert.Nil(t, err)
+		assert.Equal(t, `level=error msg=failed error=timeout error_type=*errors.errorString`, string(b))
+	})
+}
+
+func TestErrorMapper(t *testing.T) {
+	var (
+		errEv   = logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), newTestError())
+		otherEv = logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), errors.New("other"))
+		plainEv = logger.NewEvent(logger.Linfo, "ok", nil)
+	)
+
+	t.Run("filter is", func(t *testing.T) {
+		f := logger.ErrorFilter(logger.ErrorIs(errTimeout))
+		got, err := f(errEv)
+		assert.Nil(t, err)
+		assert.Equal(t, errEv, got)
+		got, err = f(otherEv)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+		got, err = f(plainEv)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("filter as", func(t *testing.T) {
+		f := logger.ErrorFilter(logger.ErrorAs[*fs.PathError]())
+		got, _ := f(errEv)
+		assert.Equal(t, errEv, got)
+		got, _ = f(otherEv)
+		assert.Nil(t, got)
+	})
+
+	t.Run("router", func(t *testing.T) {
+		var routed, passed []logger.Event
+		m := logger.ErrorRouter(
+			logger.ErrorIs(fs.ErrNotExist),
+			logger.MustNewMapperFunc(func(ev logger.Event) { routed = append(routed, ev) }),
+		).Next(func(ev logger.Event) { passed = append(passed, ev) })
+		for _, ev := range []logger.Event{errEv, otherEv, plainEv} {
+			_, err := m.Call(ev)
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, []logger.Event{errEv}, routed)
+		assert.Equal(t, []logger.Event{otherEv, plainEv}, passed)
+	})
+}
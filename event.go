@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Field is a key-value pair of the structured data of an event.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// Extra is the additional data of an event other than level, format and args.
+type Extra struct {
+	// Time is when the event occurred.
+	Time time.Time
+	// PC is the program counter of the call site, 0 if not recorded.
+	PC uintptr
+	// Name is the name of the logger.
+	Name string
+	// Fields are the structured data, written by the encoders.
+	Fields []Field
+	// Err is the error of the event, kept as it is to render the unwrap chain, see `WithError`.
+	// `Logger` attaches the first error of the args.
+	Err error
+	// Stack is the stack trace of the event, see `StackConfig`.
+	Stack []runtime.Frame
+	// Sanitized is true if the message and the name are already escaped by `SanitizeMapper`.
+	Sanitized bool
+	// Context is the context of the log call like `Logger.InfoContext`, nil if not given, see `ContextOf`.
+	Context context.Context
+}
+
+// Frame returns the call site of the event.
+// Return false if the call site is not recorded.
+func (x Extra) Frame() (runtime.Frame, bool) {
+	if x.PC == 0 {
+		return runtime.Frame{}, false
+	}
+	frames := runtime.CallersFrames([]uintptr{x.PC})
+	for {
+		f, more := frames.Next()
+		// skip the library functions inlined into the caller
+		if !isLibraryFunc(f.Function) || !more {
+			return f, true
+		}
+	}
+}
+
+type extraEvent struct {
+	Event
+	extra Extra
+}
+
+func (e *extraEvent) String() string { return fmt.Sprintf(e.Format(), e.Args()...) }
+
+// WithExtra returns a new event with the extra data.
+func WithExtra(ev Event, extra Extra) Event {
+	if e, ok := ev.(*extraEvent); ok {
+		ev = e.Event
+	}
+	return &extraEvent{
+		Event: ev,
+		extra: extra,
+	}
+}
+
+// ExtraOf returns the extra data of the event.
+// Return zero value if the event has no extra data.
+func ExtraOf(ev Event) Extra {
+	if e, ok := ev.(*extraEvent); ok {
+		return e.extra
+	}
+	return Extra{}
+}
+
+// WithContext returns a new event with the context attached, see `Extra.Context`.
+func WithContext(ev Event, ctx context.Context) Event {
+	extra := ExtraOf(ev)
+	extra.Context = ctx
+	return WithExtra(ev, extra)
+}
+
+// ContextOf returns the context of the event, `context.Background` if not attached.
+// Mappers read the request-scoped values from it, and the blocking consumers respect its cancellation.
+func ContextOf(ev Event) context.Context {
+	if ctx := ExtraOf(ev).Context; ctx != nil {
+		return ctx
+	}
+	return context.Background()
+}
+
+// AddFields returns a new event with the fields appended to the extra data of ev.
+func AddFields(ev Event, fields ...Field) Event {
+	extra := ExtraOf(ev)
+	fs := make([]Field, 0, len(extra.Fields)+len(fields))
+	fs = append(fs, extra.Fields...)
+	extra.Fields = append(fs, fields...)
+	return WithExtra(ev, extra)
+}
+
+// DeriveEvent returns a new event with the level, format and args,
+// inheriting the extra data of ev.
+//
+// Mappers that rebuild events should use this instead of NewEvent
+// so that the downstream encoders can see the time and the call site.
+func DeriveEvent(ev Event, level Level, format string, args []any) Event {
+	x := NewEvent(level, format, args)
+	if e, ok := ev.(*extraEvent); ok {
+		return &extraEvent{
+			Event: x,
+			extra: e.extra,
+		}
+	}
+	return x
+}
+
+const modulePath = "github.com/berquerant/logger"
+
+// isLibraryFunc returns true if the function belongs to this module, except tests.
+func isLibraryFunc(name string) bool {
+	if !strings.HasPrefix(name, modulePath) {
+		return false
+	}
+	rest := name[len(modulePath):]
+	switch {
+	case strings.HasPrefix(rest, "."):
+		return true
+	case strings.HasPrefix(rest, "/"):
+		pkg := rest[1:]
+		if i := strings.Index(pkg, "."); i >= 0 {
+			pkg = pkg[:i]
+		}
+		return !strings.HasSuffix(pkg, "_test")
+	default:
+		return false
+	}
+}
+
+// callerPC returns the program counter of the first frame outside of this library.
+func callerPC(skip int) uintptr {
+	var pcs [16]uintptr
+	n := runtime.Callers(skip+1, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		if !isLibraryFunc(f.Function) {
+			// f.PC is the call instruction, +1 makes it a return address like runtime.Callers
+			return f.PC + 1
+		}
+		if !more {
+			return 0
+		}
+	}
+}
@@ -7,7 +7,7 @@ import (
 	"github.com/berquerant/logger"
 )
 
-func ExampleMapper_Via() {
+func ExampleMapperFunc_Via() {
 	levelToPrefix := func(ev logger.Event) logger.Event {
 		var p string
 		switch ev.Level() {
@@ -67,7 +67,7 @@ func ExampleMapper_Via() {
 	// Err: 2 GotError: ERROR error msg
 }
 
-func ExampleMapper_Next() {
+func ExampleMapperFunc_Next() {
 	levelToPrefix := func(ev logger.Event) logger.Event {
 		var p string
 		switch ev.Level() {
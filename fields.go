@@ -0,0 +1,85 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FieldsStyle is how the encoders write the fields of the events, see `EncoderConfig.Fields`.
+type FieldsStyle string
+
+const (
+	// FieldsInline writes the fields like key=value in the text formats, as the top-level keys in JSON.
+	// In JSON and logfmt, the keys that collide with the reserved keys like `KeyLevel` are prefixed like "fields.level".
+	FieldsInline FieldsStyle = "inline"
+	// FieldsJSON writes the fields as a JSON object, like `message | {"key":"value"}` in the text format,
+	// the value of `EncoderConfig.FieldsKey` in JSON and logfmt.
+	FieldsJSON FieldsStyle = "json"
+	// FieldsOmit does not write the fields.
+	FieldsOmit FieldsStyle = "omit"
+)
+
+// KeyFields is the default key of `FieldsJSON`.
+const KeyFields = "fields"
+
+var ErrInvalidFieldsStyle = errors.New("InvalidFieldsStyle")
+
+// ParseFieldsStyle converts a string into a style, empty means inline.
+func ParseFieldsStyle(s string) (FieldsStyle, error) {
+	switch x := FieldsStyle(strings.ToLower(strings.TrimSpace(s))); x {
+	case "", FieldsInline:
+		return FieldsInline, nil
+	case FieldsJSON, FieldsOmit:
+		return x, nil
+	default:
+		return "", fmt.Errorf("%w %q", ErrInvalidFieldsStyle, s)
+	}
+}
+
+func (c EncoderConfig) fieldsStyle() FieldsStyle {
+	if c.Fields == "" {
+		return FieldsInline
+	}
+	return c.Fields
+}
+
+func (c EncoderConfig) fieldsKey() string {
+	if c.FieldsKey == "" {
+		return KeyFields
+	}
+	return c.FieldsKey
+}
+
+// inlineKey returns the key of the inline field of the structured formats.
+// The keys that collide with the reserved ones like "level" are prefixed by `fieldsKey` like "fields.level",
+// not to write the duplicate keys.
+func (c EncoderConfig) inlineKey(key string) string {
+	if isReservedKey(key) {
+		return c.fieldsKey() + "." + key
+	}
+	return key
+}
+
+// fieldsJSON encodes the fields into a JSON object in order.
+func fieldsJSON(fields []Field) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, f := range fields {
+		v, err := json.Marshal(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f.Key, err)
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, _ := json.Marshal(f.Key)
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
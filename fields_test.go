@@ -0,0 +1,139 @@
+package logger_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldsStyle(t *testing.T) {
+	ev := logger.AddFields(
+		logger.NewEvent(logger.Linfo, "served", nil),
+		logger.Field{Key: "status", Value: 200},
+		logger.Field{Key: "path", Value: "/a b"},
+	)
+
+	for _, tc := range []struct {
+		title  string
+		format logger.Format
+		cfg    logger.EncoderConfig
+		want   string
+	}{
+		{
+			title:  "text inline",
+			format: logger.FormatText,
+			want:   `I | served status=200 path="/a b"`,
+		},
+		{
+			title:  "text json",
+			format: logger.FormatText,
+			cfg:    logger.EncoderConfig{Fields: logger.FieldsJSON},
+			want:   `I | served | {"status":200,"path":"/a b"}`,
+		},
+		{
+			title:  "text omit",
+			format: logger.FormatText,
+			cfg:    logger.EncoderConfig{Fields: logger.FieldsOmit},
+			want:   `I | served`,
+		},
+		{
+			title:  "json inline",
+			format: logger.FormatJSON,
+			want:   `{"level":"info","msg":"served","status":200,"path":"/a b"}`,
+		},
+		{
+			title:  "json nested",
+			format: logger.FormatJSON,
+			cfg:    logger.EncoderConfig{Fields: logger.FieldsJSON},
+			want:   `{"level":"info","msg":"served","fields":{"status":200,"path":"/a b"}}`,
+		},
+		{
+			title:  "json nested with key",
+			format: logger.FormatJSON,
+			cfg: logger.EncoderConfig{
+				Fields:    logger.FieldsJSON,
+				FieldsKey: "data",
+			},
+			want: `{"level":"info","msg":"served","data":{"status":200,"path":"/a b"}}`,
+		},
+		{
+			title:  "json omit",
+			format: logger.FormatJSON,
+			cfg:    logger.EncoderConfig{Fields: logger.FieldsOmit},
+			want:   `{"level":"info","msg":"served"}`,
+		},
+		{
+			title:  "logfmt inline",
+			format: logger.FormatLogfmt,
+			want:   `level=info msg=served status=200 path="/a b"`,
+		},
+		{
+			title:  "logfmt json",
+			format: logger.FormatLogfmt,
+			cfg:    logger.EncoderConfig{Fields: logger.FieldsJSON},
+			want:   `level=info msg=served fields="{\"status\":200,\"path\":\"/a b\"}"`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			enc, err := logger.NewEncoder(tc.format, tc.cfg)
+			assert.Nil(t, err)
+			got, err := enc(ev)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+
+	t.Run("reserved keys", func(t *testing.T) {
+		ev := logger.AddFields(
+			logger.NewEvent(logger.Linfo, "hello", nil),
+			logger.Field{Key: "level", Value: "db"},
+			logger.Field{Key: "msg", Value: "m"},
+			logger.Field{Key: "time", Value: "now"},
+		)
+		for _, tc := range []struct {
+			format logger.Format
+			want   string
+		}{
+			{
+				format: logger.FormatJSON,
+				want:   `{"level":"info","msg":"hello","fields.level":"db","fields.msg":"m","fields.time":"now"}`,
+			},
+			{
+				format: logger.FormatLogfmt,
+				want:   `level=info msg=hello fields.level=db fields.msg=m fields.time=now`,
+			},
+		} {
+			enc, err := logger.NewEncoder(tc.format, logger.EncoderConfig{})
+			assert.Nil(t, err)
+			got, err := enc(ev)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(got))
+			r, err := logger.ParseRecord(got)
+			if assert.Nil(t, err) {
+				assert.Equal(t, logger.Linfo, r.Level)
+				assert.Equal(t, "hello", r.Msg)
+				v, _ := r.Field("fields.level")
+				assert.Equal(t, "db", v)
+			}
+		}
+	})
+
+	t.Run("marshal error", func(t *testing.T) {
+		enc, err := logger.NewEncoder(logger.FormatJSON, logger.EncoderConfig{Fields: logger.FieldsJSON})
+		assert.Nil(t, err)
+		_, err = enc(logger.AddFields(ev, logger.Field{Key: "f", Value: func() {}}))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("parse", func(t *testing.T) {
+		for _, s := range []string{"", "inline", "json", "omit"} {
+			_, err := logger.ParseFieldsStyle(s)
+			assert.Nil(t, err, s)
+		}
+		_, err := logger.ParseFieldsStyle("yaml")
+		assert.True(t, errors.Is(err, logger.ErrInvalidFieldsStyle))
+	})
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LazyValue is an arg or a field value evaluated only when it is formatted,
+// to avoid the expensive work for the dropped events.
+//
+//	l.Debug("state %v", logger.LazyValue(func() any { return dump(state) }))
+type LazyValue func() any
+
+// Format formats the value with the verb and the flags.
+func (v LazyValue) Format(f fmt.State, verb rune) {
+	fmt.Fprintf(f, formatDirective(f, verb), v())
+}
+
+func (v LazyValue) String() string { return fmt.Sprint(v()) }
+
+func (v LazyValue) MarshalJSON() ([]byte, error) { return json.Marshal(v()) }
+
+// formatDirective rebuilds the directive like "%-8.3f" from the state.
+func formatDirective(f fmt.State, verb rune) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, flag := range "+-# 0" {
+		if f.Flag(int(flag)) {
+			b.WriteRune(flag)
+		}
+	}
+	if w, ok := f.Width(); ok {
+		b.WriteString(strconv.Itoa(w))
+	}
+	if p, ok := f.Precision(); ok {
+		b.WriteByte('.')
+		b.WriteString(strconv.Itoa(p))
+	}
+	b.WriteRune(verb)
+	return b.String()
+}
@@ -0,0 +1,135 @@
+package logger_test
+
+import (
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyValue(t *testing.T) {
+	var called int
+	v := logger.LazyValue(func() any {
+		called++
+		return 3.14159
+	})
+
+	l := &logger.Logger{
+		Proxy: logger.NewLevelProxy(logger.Linfo, logger.MustNewMapperFunc(func(ev logger.Event) {
+			assert.Equal(t, "pi  3.14|3.14159", fmt.Sprint(ev))
+		})),
+	}
+	l.Debug("pi %v", v)
+	assert.Equal(t, 0, called)
+	l.Info("pi %5.2f|%v", v, v)
+	assert.Equal(t, 2, called)
+
+	enc := logger.NewJSONEncoder(logger.EncoderConfig{})
+	b, err := enc(logger.AddFields(logger.NewEvent(logger.Linfo, "msg", nil), logger.Field{Key: "pi", Value: v}))
+	assert.Nil(t, err)
+	assert.Equal(t, `{"level":"info","msg":"msg","pi":3.14159}`, string(b))
+}
+
+func TestLoggerEnabled(t *testing.T) {
+	t.Run("level proxy", func(t *testing.T) {
+		var got []string
+		l := &logger.Logger{
+			Proxy: logger.NewLevelProxy(logger.Linfo, logger.MustNewMapperFunc(func(ev logger.Event) {
+				got = append(got, fmt.Sprint(ev))
+			})),
+		}
+		assert.True(t, l.Enabled(logger.Lerror))
+		assert.True(t, l.Enabled(logger.Linfo))
+		assert.False(t, l.Enabled(logger.Ldebug))
+		l.Info("info")
+		l.Debug("debug")
+		assert.Equal(t, []string{"info"}, got)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		l := &logger.Logger{
+			Proxy: logger.NewProxy(logger.LogLevelFilter(logger.Linfo)),
+		}
+		assert.True(t, l.Enabled(logger.Ltrace))
+	})
+
+	t.Run("pipeline", func(t *testing.T) {
+		config, err := logger.ParsePipelineConfig([]byte(`
+stages:
+  - type: level
+    params:
+      level: warn
+  - type: encode
+`))
+		assert.Nil(t, err)
+		p, err := logger.Build(config)
+		assert.Nil(t, err)
+		l := &logger.Logger{Proxy: p}
+		assert.True(t, l.Enabled(logger.Lwarn))
+		assert.False(t, l.Enabled(logger.Linfo))
+	})
+
+	t.Run("global", func(t *testing.T) {
+		g := logger.G()
+		level := g.Level()
+		defer g.SetLevel(level)
+		g.SetLevel(logger.Lerror)
+		assert.False(t, g.Enabled(logger.Lwarn))
+		g.SetLevel(logger.Ldebug)
+		assert.True(t, g.Enabled(logger.Lwarn))
+	})
+}
+
+type expensive map[string]int
+
+func (e expensive) String() string { return fmt.Sprint(map[string]int(e)) }
+
+func newBenchLogger(proxy func(logger.MapperFunc) logger.Proxy) *logger.Logger {
+	return &logger.Logger{
+		Proxy: proxy(logger.MustNewMapperFunc(logger.NewWriterConsumer(io.Discard, logger.NewTextEncoder(logger.EncoderConfig{})))),
+	}
+}
+
+func BenchmarkDisabled(b *testing.B) {
+	data := expensive{"a": 1, "b": 2, "c": 3}
+	b.Run("filter mapper", func(b *testing.B) {
+		l := newBenchLogger(func(m logger.MapperFunc) logger.Proxy {
+			return logger.NewProxy(logger.LogLevelFilter(logger.Linfo).Next(m))
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug("data %s", data.String())
+		}
+	})
+	b.Run("level proxy", func(b *testing.B) {
+		l := newBenchLogger(func(m logger.MapperFunc) logger.Proxy {
+			return logger.NewLevelProxy(logger.Linfo, m)
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug("data %s", data.String())
+		}
+	})
+	b.Run("level proxy and lazy", func(b *testing.B) {
+		l := newBenchLogger(func(m logger.MapperFunc) logger.Proxy {
+			return logger.NewLevelProxy(logger.Linfo, m)
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			l.Debug("data %s", logger.LazyValue(func() any { return data.String() }))
+		}
+	})
+	b.Run("enabled", func(b *testing.B) {
+		l := newBenchLogger(func(m logger.MapperFunc) logger.Proxy {
+			return logger.NewLevelProxy(logger.Linfo, m)
+		})
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if l.Enabled(logger.Ldebug) {
+				l.Debug("data %s", data.String())
+			}
+		}
+	})
+}
@@ -0,0 +1,131 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// LevelInfo describes a level.
+type LevelInfo struct {
+	Level Level
+	// Name is like "info", used by the structured formats.
+	Name string
+	// Short is like "I", used by the prefix.
+	Short string
+}
+
+type levelRegistry struct {
+	mux    sync.RWMutex
+	levels map[Level]LevelInfo
+	// prefixes caches the prefixes like "I |"
+	prefixes map[Level]string
+}
+
+var levels = newLevelRegistry(
+	LevelInfo{Level: Lsilent, Name: "silent", Short: "S"},
+	LevelInfo{Level: Lerror, Name: "error", Short: "E"},
+	LevelInfo{Level: Lwarn, Name: "warn", Short: "W"},
+	LevelInfo{Level: Linfo, Name: "info", Short: "I"},
+	LevelInfo{Level: Ldebug, Name: "debug", Short: "D"},
+	LevelInfo{Level: Ltrace, Name: "trace", Short: "T"},
+)
+
+func newLevelRegistry(infos ...LevelInfo) *levelRegistry {
+	r := &levelRegistry{
+		levels:   map[Level]LevelInfo{},
+		prefixes: map[Level]string{},
+	}
+	for _, x := range infos {
+		r.add(x)
+	}
+	return r
+}
+
+func (r *levelRegistry) add(info LevelInfo) {
+	r.levels[info.Level] = info
+	if info.Level != Lsilent {
+		r.prefixes[info.Level] = info.Short + " |"
+	}
+}
+
+// prefix returns the prefix like "I |".
+func (r *levelRegistry) prefix(level Level) string {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	if x, ok := r.prefixes[level]; ok {
+		return x
+	}
+	return "? |"
+}
+
+var ErrDuplicateLevel = errors.New("DuplicateLevel")
+
+// RegisterLevel adds a custom level.
+// The name and the short name are case-insensitive and must be unique.
+func RegisterLevel(info LevelInfo) error {
+	levels.mux.Lock()
+	defer levels.mux.Unlock()
+	for _, x := range levels.levels {
+		if x.Level == info.Level ||
+			strings.EqualFold(x.Name, info.Name) ||
+			strings.EqualFold(x.Short, info.Short) {
+			return fmt.Errorf("%w %v", ErrDuplicateLevel, info)
+		}
+	}
+	levels.add(info)
+	return nil
+}
+
+// LookupLevel returns the description of the level.
+func LookupLevel(level Level) (LevelInfo, bool) {
+	levels.mux.RLock()
+	defer levels.mux.RUnlock()
+	x, ok := levels.levels[level]
+	return x, ok
+}
+
+// Levels returns the registered levels in ascending order.
+func Levels() []LevelInfo {
+	levels.mux.RLock()
+	defer levels.mux.RUnlock()
+	xs := make([]LevelInfo, 0, len(levels.levels))
+	for _, x := range levels.levels {
+		xs = append(xs, x)
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i].Level < xs[j].Level })
+	return xs
+}
+
+func (l Level) String() string {
+	if x, ok := LookupLevel(l); ok {
+		return x.Name
+	}
+	return strconv.Itoa(int(l))
+}
+
+var ErrInvalidLevel = errors.New("InvalidLevel")
+
+// ParseLevel converts a string into a level.
+// Accepts the level names ("info"), the short names ("I") and integers, case-insensitive.
+func ParseLevel(s string) (Level, error) {
+	t := strings.TrimSpace(s)
+	if strings.EqualFold(t, "warning") {
+		return Lwarn, nil
+	}
+	levels.mux.RLock()
+	for _, x := range levels.levels {
+		if strings.EqualFold(x.Name, t) || strings.EqualFold(x.Short, t) {
+			levels.mux.RUnlock()
+			return x.Level, nil
+		}
+	}
+	levels.mux.RUnlock()
+	if x, err := strconv.Atoi(t); err == nil {
+		return Level(x), nil
+	}
+	return Lsilent, fmt.Errorf("%w %q", ErrInvalidLevel, s)
+}
@@ -0,0 +1,42 @@
+package logger_test
+
+import (
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLevel(t *testing.T) {
+	for _, tc := range []struct {
+		input string
+		want  logger.Level
+		err   error
+	}{
+		{input: "info", want: logger.Linfo},
+		{input: "WARN", want: logger.Lwarn},
+		{input: "E", want: logger.Lerror},
+		{input: "trace", want: logger.Ltrace},
+		{input: "45", want: logger.Level(45)},
+		{input: "loud", err: logger.ErrInvalidLevel},
+	} {
+		tc := tc
+		t.Run(tc.input, func(t *testing.T) {
+			got, err := logger.ParseLevel(tc.input)
+			if tc.err != nil {
+				assert.ErrorIs(t, err, tc.err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.want, must(logger.ParseLevel(got.String())))
+		})
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
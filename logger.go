@@ -1,9 +1,13 @@
 package logger
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Proxy interface {
@@ -11,26 +15,83 @@ type Proxy interface {
 	SetErrConsumer(func(error))
 }
 
+// LevelEnabler is implemented by the proxies that know whether the events of the level are dropped.
+type LevelEnabler interface {
+	Enabled(level Level) bool
+}
+
 type proxy struct {
 	mapper      MapperFunc
-	errConsumer func(error)
+	errConsumer atomic.Pointer[func(error)]
+	// enabled drops the events before the mapper if not nil
+	enabled func(Level) bool
+	// reuse is true if the mapper never keeps the events, see `transientProxy`
+	reuse bool
+	// closer is the output opened for the proxy, see `Logger.Close`
+	closer io.Closer
+}
+
+// ProxyOption customizes the Proxy.
+type ProxyOption func(*proxyOptions)
+
+type proxyOptions struct {
+	recover bool
+}
+
+// WithRecover turns the panics of the mapper into the errors for the err consumer, see `MapperFunc.Recover`.
+func WithRecover() ProxyOption {
+	return func(o *proxyOptions) {
+		o.recover = true
+	}
 }
 
-func NewProxy(mapper MapperFunc) Proxy {
+func NewProxy(mapper MapperFunc, opts ...ProxyOption) Proxy {
+	var o proxyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.recover {
+		mapper = mapper.Recover("")
+	}
 	return &proxy{
 		mapper: mapper,
 	}
 }
 
-func (p *proxy) SetErrConsumer(errConsumer func(error)) { p.errConsumer = errConsumer }
+// NewLevelProxy returns a Proxy that drops the events with the lower level than level without calling mapper.
+// This is `LogLevelFilter` reported by `LevelEnabler`.
+func NewLevelProxy(level Level, mapper MapperFunc) Proxy { return newLevelProxy(level, mapper) }
+
+func newLevelProxy(level Level, mapper MapperFunc) *proxy {
+	return &proxy{
+		mapper:  mapper,
+		enabled: func(x Level) bool { return x <= level },
+	}
+}
+
+func (p *proxy) Enabled(level Level) bool { return p.enabled == nil || p.enabled(level) }
+func (p *proxy) transient() bool          { return p.reuse }
+
+func (p *proxy) Close() error {
+	if p.closer == nil {
+		return nil
+	}
+	return p.closer.Close()
+}
+
+// SetErrConsumer is safe to call while the stages report the errors from their goroutines.
+func (p *proxy) SetErrConsumer(errConsumer func(error)) { p.errConsumer.Store(&errConsumer) }
 
 func (p *proxy) consumeErr(err error) {
-	if p.errConsumer != nil {
-		p.errConsumer(err)
+	if f := p.errConsumer.Load(); f != nil && *f != nil {
+		(*f)(err)
 	}
 }
 
 func (p *proxy) Put(ev Event) {
+	if !p.Enabled(ev.Level()) {
+		return
+	}
 	if _, err := p.mapper.Call(ev); err != nil {
 		p.consumeErr(err)
 	}
@@ -57,48 +118,97 @@ const (
 
 type Logger struct {
 	Proxy
+	// Caller enables to record the call site of the events.
+	Caller bool
+	// Name is the name of the events.
+	Name string
+	// Stack enables to record the stack traces, nil disables.
+	Stack *StackConfig
+	// Repanic makes `Recover` panic again after logging, otherwise the panic is swallowed.
+	Repanic bool
+}
+
+// Enabled returns false if the events of the level are dropped, see `LevelEnabler`.
+// Use this to skip the expensive work only for the logs, see also `LazyValue`.
+func (l *Logger) Enabled(level Level) bool {
+	if x, ok := l.Proxy.(LevelEnabler); ok {
+		return x.Enabled(level)
+	}
+	return true
+}
+
+func (l *Logger) put(ctx context.Context, level Level, format string, v []any) {
+	if !l.Enabled(level) {
+		return
+	}
+	extra := Extra{
+		Time:    time.Now(),
+		Name:    l.Name,
+		Err:     firstError(v),
+		Context: ctx,
+	}
+	if l.Caller {
+		extra.PC = callerPC(2)
+	}
+	if l.Stack.enabled(level) {
+		extra.Stack = l.Stack.stackOf(extra.Err, 2)
+	}
+	if p, ok := l.Proxy.(transientProxy); ok && p.transient() {
+		b := getEvent(level, format, v, extra)
+		l.Put(&b.extra)
+		putEvent(b)
+		return
+	}
+	l.Put(WithExtra(NewEvent(level, format, v), extra))
 }
 
 func (l *Logger) Info(format string, v ...any) {
-	l.Put(NewEvent(Linfo, format, v))
+	l.put(nil, Linfo, format, v)
 }
 
 func (l *Logger) Warn(format string, v ...any) {
-	l.Put(NewEvent(Lwarn, format, v))
+	l.put(nil, Lwarn, format, v)
 }
 
 func (l *Logger) Error(format string, v ...any) {
-	l.Put(NewEvent(Lerror, format, v))
+	l.put(nil, Lerror, format, v)
 }
 
 func (l *Logger) Debug(format string, v ...any) {
-	l.Put(NewEvent(Ldebug, format, v))
+	l.put(nil, Ldebug, format, v)
 }
 
 func (l *Logger) Trace(format string, v ...any) {
-	l.Put(NewEvent(Ltrace, format, v))
+	l.put(nil, Ltrace, format, v)
 }
 
-func logLevelToPrefix(level Level) string {
-	switch level {
-	case Linfo:
-		return "I |"
-	case Lwarn:
-		return "W |"
-	case Lerror:
-		return "E |"
-	case Ldebug:
-		return "D |"
-	case Ltrace:
-		return "T |"
-	default:
-		return "? |"
-	}
+// InfoContext is Info with the context, see `ContextOf`.
+func (l *Logger) InfoContext(ctx context.Context, format string, v ...any) {
+	l.put(ctx, Linfo, format, v)
+}
+
+func (l *Logger) WarnContext(ctx context.Context, format string, v ...any) {
+	l.put(ctx, Lwarn, format, v)
+}
+
+func (l *Logger) ErrorContext(ctx context.Context, format string, v ...any) {
+	l.put(ctx, Lerror, format, v)
+}
+
+func (l *Logger) DebugContext(ctx context.Context, format string, v ...any) {
+	l.put(ctx, Ldebug, format, v)
 }
 
+func (l *Logger) TraceContext(ctx context.Context, format string, v ...any) {
+	l.put(ctx, Ltrace, format, v)
+}
+
+func logLevelToPrefix(level Level) string { return levels.prefix(level) }
+
 // LogLevelToPrefixMapper adds a prefix depending on the event level.
 func LogLevelToPrefixMapper(ev Event) (Event, error) {
-	return NewEvent(
+	return DeriveEvent(
+		ev,
 		ev.Level(),
 		fmt.Sprintf("%s %s", logLevelToPrefix(ev.Level()), ev.Format()),
 		ev.Args(),
@@ -111,48 +221,134 @@ func StandardLogConsumer(ev Event) (Event, error) {
 	return ev, nil
 }
 
-// NewDefault returns a new logger with `LogLevelFilter`, `LogLevelToPrefixMapper` and `StandardLogConsumer`.
+// standardLogMapper is `SanitizeMapper`, `LogLevelToPrefixMapper` and `StandardLogConsumer`
+// without rebuilding the event, by the pooled buffer.
+// If the event has the stack trace, the unwrap tree of the error and the stack trace follow
+// as the indented lines like `NewTextEncoder`.
+func standardLogMapper(ev Event) (Event, error) {
+	b := getBuffer()
+	defer putBuffer(b)
+	extra := ExtraOf(ev)
+	b.WriteString(logLevelToPrefix(ev.Level()))
+	b.WriteByte(' ')
+	if extra.Sanitized {
+		fmt.Fprintf(b, ev.Format(), ev.Args()...)
+	} else {
+		// escape the args only since the format is trusted
+		a := getSanitizedArgs(ev.Format(), ev.Args())
+		fmt.Fprintf(b, ev.Format(), a.args...)
+		putSanitizedArgs(a)
+	}
+	if len(extra.Stack) > 0 {
+		// opted in by `Logger.Stack` or by `Logger.Recover`, otherwise an event is a line
+		if extra.Err != nil {
+			if n := newErrorNode(extra.Err, 0); len(n.Causes) > 0 {
+				writeErrorTree(b, n, EncoderConfig{}, 0)
+			}
+		}
+		writeStack(b, extra.Stack)
+	}
+	_ = log.Output(2, bytesToString(b.Bytes()))
+	return ev, nil
+}
+
+// NewDefault returns a new logger that works like `LogLevelFilter`, `SanitizeMapper`, `LogLevelToPrefixMapper` and `StandardLogConsumer`.
+// The events and the buffers are reused, logging allocates nothing but the args.
 func NewDefault(level Level) *Logger {
+	p := newLevelProxy(level, standardLogMapper)
+	p.reuse = true
 	return &Logger{
-		Proxy: NewProxy(
-			MustNewMapperFunc(LogLevelFilter(level)).Next(LogLevelToPrefixMapper).Next(StandardLogConsumer),
-		),
+		Proxy: p,
 	}
 }
 
+// NewDefaultFromEnv returns a new logger configured by the environment variables, see `EnvConfig`.
+// level is used when `EnvLevel` is not set.
+// Without the variables, this is the same as `NewDefault`.
+// Invalid values are ignored and reported to errConsumer.
+// Close the logger to close the output file of `EnvOutput`.
+func NewDefaultFromEnv(level Level, errConsumer func(error)) *Logger {
+	c, consumer, closer, errs := newEnvConsumer(level)
+	p := newLevelProxy(c.Level, consumer)
+	p.reuse = true
+	p.closer = closer
+	l := &Logger{
+		Proxy:  p,
+		Caller: c.Caller,
+	}
+	l.SetErrConsumer(errConsumer)
+	if errConsumer != nil {
+		for _, err := range errs {
+			errConsumer(err)
+		}
+	}
+	return l
+}
+
 // GlobalLogger is a static logger instance.
-// This filters logs by level, adds a prefix depending on event level
-// and writes logs by `log.Printf`.
+// This filters logs by level, and by default adds a prefix depending on event level
+// and writes logs by the standard log, like `NewDefault`.
+//
+// The environment variables configure this at the first call of `G`, see `EnvConfig`.
+// `EnvFormat` and `EnvOutput` replace the standard log with the encoder and the destination.
+// Invalid values are reported when the err consumer is set.
+//
+// The methods may be added, the implementations outside this module need to follow them.
 type GlobalLogger interface {
 	Info(format string, v ...any)
 	Warn(format string, v ...any)
 	Error(format string, v ...any)
 	Debug(format string, v ...any)
 	Trace(format string, v ...any)
+	InfoContext(ctx context.Context, format string, v ...any)
+	WarnContext(ctx context.Context, format string, v ...any)
+	ErrorContext(ctx context.Context, format string, v ...any)
+	DebugContext(ctx context.Context, format string, v ...any)
+	TraceContext(ctx context.Context, format string, v ...any)
 	SetLevel(level Level)
 	Level() Level
+	Enabled(level Level) bool
+	SetErrConsumer(func(error))
+	// Put passes the event to the proxy.
+	Put(event Event)
 }
 
 type globalLogger struct {
 	*Logger
-	level Level
+	level   Level
+	envErrs []error
 }
 
-func (g *globalLogger) logLevelFilter(ev Event) (Event, error) {
-	return LogLevelFilter(g.level)(ev)
-}
+func (g *globalLogger) enabled(level Level) bool { return level <= g.level }
 
 func (g *globalLogger) SetLevel(level Level) { g.level = level }
 func (g *globalLogger) Level() Level         { return g.level }
 
+func (g *globalLogger) SetErrConsumer(errConsumer func(error)) {
+	g.Logger.SetErrConsumer(errConsumer)
+	if errConsumer == nil {
+		return
+	}
+	for _, err := range g.envErrs {
+		errConsumer(err)
+	}
+	g.envErrs = nil
+}
+
 func newGlobalLogger() *globalLogger {
+	c, consumer, _, errs := newEnvConsumer(Linfo) // the global logger lives until the process exits
 	g := &globalLogger{
-		level:  Linfo,
-		Logger: &Logger{},
+		level: c.Level,
+		Logger: &Logger{
+			Caller: c.Caller,
+		},
+		envErrs: errs,
+	}
+	g.Proxy = &proxy{
+		mapper:  consumer,
+		enabled: g.enabled,
+		reuse:   true,
 	}
-	g.Proxy = NewProxy(
-		MustNewMapperFunc(g.logLevelFilter).Next(LogLevelToPrefixMapper).Next(StandardLogConsumer),
-	)
 	return g
 }
 
@@ -1,7 +1,11 @@
 package logger_test
 
 import (
+	"bytes"
 	"fmt"
+	"log"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/berquerant/logger"
@@ -101,3 +105,36 @@ func TestLogLevelFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestNewDefault(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		w     = log.Writer()
+		flags = log.Flags()
+	)
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+	}()
+
+	l := logger.NewDefault(logger.Linfo)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				l.Info("n=%d", 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, strings.Repeat("I | n=1\n", 400), buf.String())
+
+	buf.Reset()
+	l.Warn("user %s\tlogin", "bob\nI | root")
+	l.Debug("ignored")
+	assert.Equal(t, "W | user bob\\nI | root\tlogin\n", buf.String())
+}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime"
 )
 
 // Level is the threshold for logging.
@@ -71,6 +72,7 @@ func (m MapperFunc) Call(event Event) (Event, error) {
 
 // Next appends a MapperFunc.
 // The returned function calls this, and f with the returned event of this, if no errors.
+// If this returns nil event without errors, that is, filters the event, f is not called.
 //
 // Available signatures of f:
 //   func(Event)
@@ -88,7 +90,7 @@ func (m MapperFunc) Next(f any) MapperFunc {
 	}
 	return func(event Event) (Event, error) {
 		event, err := m.Call(event)
-		if err != nil {
+		if err != nil || event == nil {
 			return nil, err
 		}
 		return mapper.Call(event)
@@ -98,6 +100,7 @@ func (m MapperFunc) Next(f any) MapperFunc {
 // Via appends a MapperFunc.
 // The returned function calls this, and f with the returned event of this, if no errors,
 // but ignores the values from f.
+// If this returns nil event without errors, f is not called.
 //
 // Available signatures of f:
 //   func(Event)
@@ -115,10 +118,60 @@ func (m MapperFunc) Via(f any) MapperFunc {
 	}
 	return func(event Event) (Event, error) {
 		event, err := m.Call(event)
-		if err != nil {
+		if err != nil || event == nil {
 			return nil, err
 		}
 		_, _ = mapper.Call(event)
 		return event, nil
 	}
 }
+
+var ErrMapperPanic = errors.New("MapperPanic")
+
+// PanicError is a panic recovered from a mapper, see `MapperFunc.Recover`.
+type PanicError struct {
+	// Stage identifies the mapper.
+	Stage string
+	// Value is the value passed to panic.
+	Value any
+	// Stack is the stack trace of the panic.
+	Stack []runtime.Frame
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v in %s: %v", ErrMapperPanic, e.Stage, e.Value)
+}
+
+func (e *PanicError) Is(target error) bool { return target == ErrMapperPanic }
+
+// Unwrap returns the value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Recover returns a MapperFunc that returns *PanicError instead of the panic of this.
+// name identifies this in the error, if empty, the name of the function that panicked.
+func (m MapperFunc) Recover(name string) MapperFunc {
+	if m == nil {
+		return nil
+	}
+	return func(event Event) (ev Event, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+			e := &PanicError{
+				Stage: name,
+				Value: r,
+				Stack: panicStack(32),
+			}
+			if e.Stage == "" && len(e.Stack) > 0 {
+				e.Stage = e.Stack[0].Function
+			}
+			ev, err = nil, e
+		}()
+		return m.Call(event)
+	}
+}
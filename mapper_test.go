@@ -3,6 +3,7 @@ package logger_test
 import (
 	"errors"
 	"fmt"
+	"io"
 	"testing"
 
 	"github.com/berquerant/logger"
@@ -195,3 +196,71 @@ func TestNewMapperFunc(t *testing.T) {
 		})
 	}
 }
+
+func TestMapperFuncFiltered(t *testing.T) {
+	ev1 := logger.NewEvent(10, "msg", nil)
+	filter := newMockMapperFunc(nil, nil)
+
+	for _, tc := range []struct {
+		title string
+		chain func(f any) logger.MapperFunc
+	}{
+		{
+			title: "next",
+			chain: logger.MustNewMapperFunc(filter.call).Next,
+		},
+		{
+			title: "via",
+			chain: logger.MustNewMapperFunc(filter.call).Via,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			f := newMockMapperFunc(ev1, nil)
+			got, err := tc.chain(f.call).Call(ev1)
+			assert.Nil(t, err)
+			assert.Nil(t, got)
+			assert.Nil(t, f.arg)
+		})
+	}
+}
+
+func TestMapperFuncRecover(t *testing.T) {
+	ev := logger.NewEvent(logger.Linfo, "msg", nil)
+	boom := func(logger.Event) (logger.Event, error) {
+		var m map[string]int
+		m["x"] = 1
+		return nil, nil
+	}
+
+	t.Run("named", func(t *testing.T) {
+		got, err := logger.MustNewMapperFunc(boom).Recover("boom").Call(ev)
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, logger.ErrMapperPanic)
+		var pe *logger.PanicError
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, "boom", pe.Stage)
+			assert.NotEmpty(t, pe.Stack)
+		}
+		assert.Equal(t, "MapperPanic in boom: assignment to entry in nil map", err.Error())
+	})
+
+	t.Run("unnamed", func(t *testing.T) {
+		_, err := logger.MustNewMapperFunc(boom).Next(logger.StandardLogConsumer).Recover("").Call(ev)
+		var pe *logger.PanicError
+		if assert.True(t, errors.As(err, &pe)) {
+			assert.Equal(t, "github.com/berquerant/logger_test.TestMapperFuncRecover.func1", pe.Stage)
+		}
+	})
+
+	t.Run("proxy", func(t *testing.T) {
+		var errs []error
+		p := logger.NewProxy(logger.MustNewMapperFunc(func(logger.Event) { panic(io.EOF) }), logger.WithRecover())
+		p.SetErrConsumer(func(err error) { errs = append(errs, err) })
+		assert.NotPanics(t, func() { p.Put(ev) })
+		if assert.Equal(t, 1, len(errs)) {
+			assert.ErrorIs(t, errs[0], logger.ErrMapperPanic)
+			assert.ErrorIs(t, errs[0], io.EOF)
+		}
+	})
+}
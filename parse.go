@@ -0,0 +1,170 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Record is a log line parsed back from the structured formats.
+type Record struct {
+	Time   time.Time
+	Level  Level
+	Name   string
+	Msg    string
+	Caller string
+	// Fields are the other key-value pairs in the order of appearance.
+	Fields []Field
+}
+
+// Event converts the record into an event, to write it again by the encoders.
+// The caller is the first field since the event records the call site by the program counter.
+func (r *Record) Event() Event {
+	fields := r.Fields
+	if r.Caller != "" {
+		fields = make([]Field, 0, len(r.Fields)+1)
+		fields = append(fields, Field{Key: KeyCaller, Value: r.Caller})
+		fields = append(fields, r.Fields...)
+	}
+	return WithExtra(NewEvent(r.Level, "%s", []any{r.Msg}), Extra{
+		Time:   r.Time,
+		Name:   r.Name,
+		Fields: fields,
+	})
+}
+
+// Field returns the value of the field.
+func (r *Record) Field(key string) (any, bool) {
+	for _, f := range r.Fields {
+		if f.Key == key {
+			return f.Value, true
+		}
+	}
+	return nil, false
+}
+
+var ErrInvalidRecord = errors.New("InvalidRecord")
+
+// ParseRecord parses a line written by the JSON encoder or the logfmt encoder.
+// A line starting with "{" is regarded as JSON.
+func ParseRecord(line []byte) (*Record, error) {
+	line = bytes.TrimSpace(line)
+	if bytes.HasPrefix(line, []byte("{")) {
+		return ParseJSONRecord(line)
+	}
+	return ParseLogfmtRecord(line)
+}
+
+// ParseJSONRecord parses a line written by the JSON encoder.
+func ParseJSONRecord(line []byte) (*Record, error) {
+	dec := json.NewDecoder(bytes.NewReader(line))
+	dec.UseNumber()
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '{' {
+		return nil, fmt.Errorf("%w: not an object", ErrInvalidRecord)
+	}
+
+	var r Record
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRecord, err)
+		}
+		key, _ := tok.(string)
+		var value any
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRecord, key, err)
+		}
+		if err := r.set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// ParseLogfmtRecord parses a line written by the logfmt encoder.
+func ParseLogfmtRecord(line []byte) (*Record, error) {
+	var (
+		r Record
+		s = string(bytes.TrimSpace(line))
+	)
+	for s != "" {
+		i := strings.IndexByte(s, '=')
+		if i <= 0 {
+			return nil, fmt.Errorf("%w: no key-value pair at %q", ErrInvalidRecord, s)
+		}
+		key := s[:i]
+		if strings.ContainsAny(key, " \"") {
+			return nil, fmt.Errorf("%w: invalid key %q", ErrInvalidRecord, key)
+		}
+		s = s[i+1:]
+
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRecord, key, err)
+			}
+			value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+		} else {
+			j := strings.IndexByte(s, ' ')
+			if j < 0 {
+				j = len(s)
+			}
+			value = s[:j]
+			s = s[j:]
+		}
+		s = strings.TrimLeft(s, " ")
+		if err := r.set(key, value); err != nil {
+			return nil, err
+		}
+	}
+	return &r, nil
+}
+
+// recordTimeFormats are tried in order to parse the time.
+var recordTimeFormats = []string{
+	time.RFC3339Nano,
+	timeFormats["datetime"],
+	timeFormats["log"],
+}
+
+func (r *Record) set(key string, value any) error {
+	switch key {
+	case KeyTime:
+		s := fmt.Sprint(value)
+		for _, layout := range recordTimeFormats {
+			if t, err := time.Parse(layout, s); err == nil {
+				r.Time = t
+				return nil
+			}
+		}
+		return fmt.Errorf("%w: %s: %q", ErrInvalidRecord, key, s)
+	case KeyLevel:
+		x, err := ParseLevel(fmt.Sprint(value))
+		if err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidRecord, key, err)
+		}
+		r.Level = x
+	case KeyName:
+		r.Name = fmt.Sprint(value)
+	case KeyMsg:
+		r.Msg = fmt.Sprint(value)
+	case KeyCaller:
+		r.Caller = fmt.Sprint(value)
+	default:
+		r.Fields = append(r.Fields, Field{
+			Key:   key,
+			Value: value,
+		})
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package logger_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRecord(t *testing.T) {
+	ev := logger.WithExtra(
+		logger.NewEvent(logger.Lerror, "failed to %s", []any{"open file"}),
+		logger.Extra{
+			Time: time.Date(2022, 9, 20, 10, 0, 0, 123, time.UTC),
+			Name: "db",
+			Fields: []logger.Field{
+				{Key: "path", Value: "/tmp/a b"},
+				{Key: "retry", Value: 3},
+			},
+		},
+	)
+	cfg := logger.EncoderConfig{
+		TimeFormat: time.RFC3339Nano,
+	}
+
+	for _, tc := range []struct {
+		title string
+		enc   logger.Encoder
+		retry any
+	}{
+		{
+			title: "json",
+			enc:   logger.NewJSONEncoder(cfg),
+			retry: json.Number("3"),
+		},
+		{
+			title: "logfmt",
+			enc:   logger.NewLogfmtEncoder(cfg),
+			retry: "3",
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			line, err := tc.enc(ev)
+			assert.Nil(t, err)
+			got, err := logger.ParseRecord(line)
+			assert.Nil(t, err)
+			assert.Equal(t, &logger.Record{
+				Time:  logger.ExtraOf(ev).Time,
+				Level: logger.Lerror,
+				Name:  "db",
+				Msg:   "failed to open file",
+				Fields: []logger.Field{
+					{Key: "path", Value: "/tmp/a b"},
+					{Key: "retry", Value: tc.retry},
+				},
+			}, got)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		for _, line := range []string{
+			`{"level":"loud"}`,
+			`{"msg":`,
+			`level=info msg`,
+			`msg="unterminated`,
+		} {
+			_, err := logger.ParseRecord([]byte(line))
+			assert.ErrorIs(t, err, logger.ErrInvalidRecord, line)
+		}
+	})
+}
@@ -0,0 +1,532 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PipelineConfig declares a pipeline as a list of stages.
+// Events pass through the stages in order.
+type PipelineConfig struct {
+	Stages []StageConfig `json:"stages" yaml:"stages"`
+}
+
+// StageConfig declares a stage of the pipeline.
+type StageConfig struct {
+	// Name identifies the stage in the errors, optional.
+	Name string `json:"name,omitempty" yaml:"name,omitempty"`
+	// Type is the name of the registered `StageBuilder`.
+	Type string `json:"type" yaml:"type"`
+	// Params are passed to the `StageBuilder`.
+	Params map[string]any `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// ParsePipelineConfig parses the config written in JSON or YAML.
+// The unknown keys are errors, the errors of the stages are `*BuildError` that points at them.
+func ParsePipelineConfig(data []byte) (*PipelineConfig, error) {
+	var raw struct {
+		Stages []yaml.Node `yaml:"stages"`
+	}
+	// YAML is a superset of JSON
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&raw); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	var (
+		c = PipelineConfig{
+			Stages: make([]StageConfig, len(raw.Stages)),
+		}
+		errs []*StageError
+	)
+	for i, node := range raw.Stages {
+		node := node
+		err := node.Decode(&c.Stages[i])
+		if err == nil {
+			err = unknownStageKey(&node)
+		}
+		if err != nil {
+			errs = append(errs, &StageError{
+				Index: i,
+				Name:  c.Stages[i].Name,
+				Type:  c.Stages[i].Type,
+				Err:   fmt.Errorf("%w: %v", ErrInvalidStage, err),
+			})
+		}
+	}
+	if len(errs) > 0 {
+		return nil, &BuildError{Errs: errs}
+	}
+	return &c, nil
+}
+
+// unknownStageKey returns an error if the stage has the keys other than the ones of `StageConfig`.
+func unknownStageKey(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		switch k := node.Content[i]; k.Value {
+		case "name", "type", "params":
+		default:
+			return fmt.Errorf("line %d: unknown key %q", k.Line, k.Value)
+		}
+	}
+	return nil
+}
+
+// ReadPipelineConfigFile reads the config file written in JSON or YAML.
+func ReadPipelineConfigFile(path string) (*PipelineConfig, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParsePipelineConfig(b)
+}
+
+// Stage is a built stage of the pipeline.
+type Stage struct {
+	Mapper MapperFunc
+	// Flush writes the buffered events, optional.
+	Flush func() error
+	// Close releases the resources, optional.
+	Close func() error
+	// Connect receives the function that passes an event to the following stages, optional.
+	// The stages that emit events by themselves, like summaries, use it.
+	Connect func(emit func(Event))
+	// Enabled returns false if the stage drops all of the events of the level, optional.
+	// The pipeline drops them before the first stage and reports it by `LevelEnabler`.
+	Enabled func(level Level) bool
+}
+
+// StageBuilder builds a stage from the parameters.
+type StageBuilder func(params *Params) (*Stage, error)
+
+var (
+	ErrUnknownStage   = errors.New("UnknownStage")
+	ErrDuplicateStage = errors.New("DuplicateStage")
+	ErrInvalidParam   = errors.New("InvalidParam")
+	ErrInvalidStage   = errors.New("InvalidStage")
+)
+
+// StageError is an error of the stage.
+type StageError struct {
+	// Index is the position of the stage in the config.
+	Index int
+	Name  string
+	Type  string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", stageIdentity(e.Index, e.Name, e.Type), e.Err)
+}
+
+// stageIdentity returns the stage like `stages[1] "out" (writer)`.
+func stageIdentity(index int, name, typ string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "stages[%d]", index)
+	if name != "" {
+		fmt.Fprintf(&b, " %q", name)
+	}
+	fmt.Fprintf(&b, " (%s)", typ)
+	return b.String()
+}
+
+func (e *StageError) Unwrap() error { return e.Err }
+
+// BuildError is the errors of the stages.
+type BuildError struct {
+	Errs []*StageError
+}
+
+func (e *BuildError) Error() string {
+	ss := make([]string, len(e.Errs))
+	for i, x := range e.Errs {
+		ss[i] = x.Error()
+	}
+	return strings.Join(ss, "; ")
+}
+
+// Is reports whether any of the stage errors matches the target.
+func (e *BuildError) Is(target error) bool {
+	for _, x := range e.Errs {
+		if errors.Is(x, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Params are the parameters of a stage.
+// The getters record the invalid values and the unknown keys are regarded as invalid.
+type Params struct {
+	values map[string]any
+	used   map[string]bool
+	errs   []error
+}
+
+func NewParams(values map[string]any) *Params {
+	return &Params{
+		values: values,
+		used:   map[string]bool{},
+	}
+}
+
+func (p *Params) get(key string) (any, bool) {
+	p.used[key] = true
+	v, ok := p.values[key]
+	return v, ok && v != nil
+}
+
+func (p *Params) invalid(key string, value any, want string) {
+	p.errs = append(p.errs, fmt.Errorf("%w %s: want %s but got %v", ErrInvalidParam, key, want, value))
+}
+
+// Has returns true if the key exists.
+func (p *Params) Has(key string) bool {
+	_, ok := p.get(key)
+	return ok
+}
+
+func (p *Params) String(key, defaultValue string) string {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	p.invalid(key, v, "string")
+	return defaultValue
+}
+
+func (p *Params) Int(key string, defaultValue int) int {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	switch v := v.(type) {
+	case int:
+		return v
+	case float64:
+		if v == float64(int(v)) {
+			return int(v)
+		}
+	}
+	p.invalid(key, v, "int")
+	return defaultValue
+}
+
+func (p *Params) Float(key string, defaultValue float64) float64 {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	p.invalid(key, v, "float")
+	return defaultValue
+}
+
+func (p *Params) Bool(key string, defaultValue bool) bool {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	if b, ok := v.(bool); ok {
+		return b
+	}
+	p.invalid(key, v, "bool")
+	return defaultValue
+}
+
+// Duration accepts a string like "10s".
+func (p *Params) Duration(key string, defaultValue time.Duration) time.Duration {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(s); err == nil {
+			return d
+		}
+	}
+	p.invalid(key, v, "duration")
+	return defaultValue
+}
+
+// Level accepts a string like "info" or an integer.
+func (p *Params) Level(key string, defaultValue Level) Level {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	if x, err := ParseLevel(fmt.Sprint(v)); err == nil {
+		return x
+	}
+	p.invalid(key, v, "level")
+	return defaultValue
+}
+
+// Strings accepts a list of strings.
+func (p *Params) Strings(key string, defaultValue []string) []string {
+	v, ok := p.get(key)
+	if !ok {
+		return defaultValue
+	}
+	xs, ok := v.([]any)
+	if !ok {
+		p.invalid(key, v, "list of string")
+		return defaultValue
+	}
+	r := make([]string, len(xs))
+	for i, x := range xs {
+		s, ok := x.(string)
+		if !ok {
+			p.invalid(key, v, "list of string")
+			return defaultValue
+		}
+		r[i] = s
+	}
+	return r
+}
+
+// Err returns the errors of the parameters.
+func (p *Params) Err() error {
+	var unknowns []string
+	for k := range p.values {
+		if !p.used[k] {
+			unknowns = append(unknowns, k)
+		}
+	}
+	sort.Strings(unknowns)
+	errs := p.errs
+	for _, k := range unknowns {
+		errs = append(errs, fmt.Errorf("%w %s: unknown", ErrInvalidParam, k))
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		ss := make([]string, len(errs))
+		for i, err := range errs {
+			ss[i] = err.Error()
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidParam, strings.Join(ss, ", "))
+	}
+}
+
+// Registry holds the stage builders by name.
+type Registry struct {
+	mux      sync.RWMutex
+	builders map[string]StageBuilder
+}
+
+// NewRegistry returns a new registry with the builtin stages.
+func NewRegistry() *Registry {
+	r := &Registry{
+		builders: map[string]StageBuilder{},
+	}
+	for name, b := range builtinStages() {
+		r.builders[name] = b
+	}
+	return r
+}
+
+// Register adds a stage builder.
+// Return ErrDuplicateStage if the name is already registered.
+func (r *Registry) Register(name string, builder StageBuilder) error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	if _, ok := r.builders[name]; ok {
+		return fmt.Errorf("%w %s", ErrDuplicateStage, name)
+	}
+	r.builders[name] = builder
+	return nil
+}
+
+func (r *Registry) lookup(name string) (StageBuilder, bool) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	b, ok := r.builders[name]
+	return b, ok
+}
+
+// BuildStages builds the stages of the config.
+// Return *BuildError including all of the invalid stages.
+// The panics of the stages are returned as *PanicError, see `MapperFunc.Recover`.
+func (r *Registry) BuildStages(config *PipelineConfig) ([]*Stage, error) {
+	var (
+		stages = make([]*Stage, 0, len(config.Stages))
+		errs   []*StageError
+	)
+	for i, sc := range config.Stages {
+		stage, err := r.buildStage(sc)
+		if err != nil {
+			errs = append(errs, &StageError{
+				Index: i,
+				Name:  sc.Name,
+				Type:  sc.Type,
+				Err:   err,
+			})
+			continue
+		}
+		// a broken stage must not crash the application
+		stage.Mapper = stage.Mapper.Recover(stageIdentity(i, sc.Name, sc.Type))
+		stages = append(stages, stage)
+	}
+	if len(errs) > 0 {
+		closeStages(stages)
+		return nil, &BuildError{Errs: errs}
+	}
+	if len(stages) == 0 {
+		return nil, &BuildError{Errs: []*StageError{{Err: fmt.Errorf("%w: no stages", ErrInvalidStage)}}}
+	}
+	return stages, nil
+}
+
+func (r *Registry) buildStage(sc StageConfig) (*Stage, error) {
+	builder, ok := r.lookup(sc.Type)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownStage, sc.Type)
+	}
+	params := NewParams(sc.Params)
+	stage, err := builder(params)
+	if err == nil {
+		err = params.Err()
+	}
+	if err == nil && (stage == nil || stage.Mapper == nil) {
+		err = fmt.Errorf("%w: no mapper", ErrInvalidStage)
+	}
+	if err != nil {
+		if stage != nil {
+			closeStages([]*Stage{stage})
+		}
+		return nil, err
+	}
+	return stage, nil
+}
+
+// Build returns a new Proxy that passes events through the stages of the config.
+// The returned Proxy implements `Flush() error` and `Close() error` of the stages, and `LevelEnabler`.
+func (r *Registry) Build(config *PipelineConfig) (Proxy, error) {
+	stages, err := r.BuildStages(config)
+	if err != nil {
+		return nil, err
+	}
+	return newPipeline(stages), nil
+}
+
+// DefaultRegistry is the registry used by `Register` and `Build`.
+var DefaultRegistry = NewRegistry()
+
+// Register adds a stage builder to `DefaultRegistry`.
+func Register(name string, builder StageBuilder) error {
+	return DefaultRegistry.Register(name, builder)
+}
+
+// Build builds a pipeline by `DefaultRegistry`.
+func Build(config *PipelineConfig) (Proxy, error) { return DefaultRegistry.Build(config) }
+
+type pipeline struct {
+	*proxy
+	stages []*Stage
+}
+
+func newPipeline(stages []*Stage) *pipeline {
+	var (
+		p = &pipeline{
+			proxy:  &proxy{},
+			stages: stages,
+		}
+		// downstream is the chain of the following stages
+		downstream MapperFunc
+	)
+	for i := len(stages) - 1; i >= 0; i-- {
+		s := stages[i]
+		if s.Connect != nil {
+			next := downstream
+			s.Connect(func(ev Event) {
+				if next == nil {
+					return
+				}
+				if _, err := next.Call(ev); err != nil {
+					p.consumeErr(err)
+				}
+			})
+		}
+		downstream = MustNewMapperFunc(s.Mapper).Next(downstream)
+	}
+	p.mapper = downstream
+	for _, s := range stages {
+		if s.Enabled != nil {
+			p.enabled = p.stagesEnabled
+			break
+		}
+	}
+	return p
+}
+
+func (p *pipeline) stagesEnabled(level Level) bool {
+	for _, s := range p.stages {
+		if s.Enabled != nil && !s.Enabled(level) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *pipeline) Flush() error { return flushStages(p.stages) }
+func (p *pipeline) Close() error { return closeStages(p.stages) }
+
+func flushStages(stages []*Stage) error {
+	var errs []string
+	for _, s := range stages {
+		if s.Flush == nil {
+			continue
+		}
+		if err := s.Flush(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// closeStages flushes and closes the stages.
+func closeStages(stages []*Stage) error {
+	var errs []string
+	if err := flushStages(stages); err != nil {
+		errs = append(errs, err.Error())
+	}
+	for _, s := range stages {
+		if s.Close == nil {
+			continue
+		}
+		if err := s.Close(); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+	return nil
+}
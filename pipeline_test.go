@@ -0,0 +1,149 @@
+package logger_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "out.log")
+	config, err := logger.ParsePipelineConfig([]byte(fmt.Sprintf(`
+stages:
+  - type: level
+    params:
+      level: warn
+  - name: upper
+    type: upper
+  - type: writer
+    params:
+      output: %s
+      format: logfmt
+`, path)))
+	assert.Nil(t, err)
+
+	r := logger.NewRegistry()
+	assert.Nil(t, r.Register("upper", func(_ *logger.Params) (*logger.Stage, error) {
+		return &logger.Stage{
+			Mapper: func(ev logger.Event) (logger.Event, error) {
+				return logger.DeriveEvent(ev, ev.Level(), strings.ToUpper(ev.Format()), ev.Args()), nil
+			},
+		}, nil
+	}))
+	assert.ErrorIs(t, r.Register("upper", nil), logger.ErrDuplicateStage)
+
+	p, err := r.Build(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var errs []error
+	p.SetErrConsumer(func(err error) { errs = append(errs, err) })
+	l := &logger.Logger{Proxy: p}
+	l.Info("ignored")
+	l.Error("disk full")
+	assert.Nil(t, p.(interface{ Close() error }).Close())
+	assert.Nil(t, errs)
+
+	got, err := os.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "level=error msg=\"DISK FULL\"\n", string(got))
+}
+
+func TestBuildValidation(t *testing.T) {
+	config, err := logger.ParsePipelineConfig([]byte(`{
+  "stages": [
+    {"type": "level", "params": {"level": "loud"}},
+    {"type": "prefix"},
+    {"name": "mystery", "type": "unknown"},
+    {"name": "out", "type": "writer", "params": {"format": "json", "colour": true}}
+  ]
+}`))
+	assert.Nil(t, err)
+
+	_, err = logger.Build(config)
+	assert.ErrorIs(t, err, logger.ErrInvalidParam)
+	assert.ErrorIs(t, err, logger.ErrUnknownStage)
+	buildErr, ok := err.(*logger.BuildError)
+	if !assert.True(t, ok) {
+		return
+	}
+	if !assert.Equal(t, 3, len(buildErr.Errs)) {
+		return
+	}
+	for i, want := range []struct {
+		index int
+		msg   string
+	}{
+		{index: 0, msg: `stages[0] (level): InvalidParam level`},
+		{index: 2, msg: `stages[2] "mystery" (unknown): UnknownStage`},
+		{index: 3, msg: `stages[3] "out" (writer): InvalidParam colour: unknown`},
+	} {
+		assert.Equal(t, want.index, buildErr.Errs[i].Index)
+		assert.Contains(t, buildErr.Errs[i].Error(), want.msg)
+	}
+}
+
+func TestBuildRecover(t *testing.T) {
+	config, err := logger.ParsePipelineConfig([]byte(`
+stages:
+  - name: broken
+    type: broken
+  - type: encode
+`))
+	assert.Nil(t, err)
+	r := logger.NewRegistry()
+	assert.Nil(t, r.Register("broken", func(_ *logger.Params) (*logger.Stage, error) {
+		return &logger.Stage{
+			Mapper: func(ev logger.Event) (logger.Event, error) {
+				panic("broken")
+			},
+		}, nil
+	}))
+	p, err := r.Build(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var errs []error
+	p.SetErrConsumer(func(err error) { errs = append(errs, err) })
+	assert.NotPanics(t, func() { p.Put(logger.NewEvent(logger.Linfo, "msg", nil)) })
+	if assert.Equal(t, 1, len(errs)) {
+		assert.Equal(t, `MapperPanic in stages[0] "broken" (broken): broken`, errs[0].Error())
+	}
+}
+
+func TestParsePipelineConfigUnknownKeys(t *testing.T) {
+	t.Run("stage", func(t *testing.T) {
+		_, err := logger.ParsePipelineConfig([]byte(`
+stages:
+  - type: prefix
+  - name: filter
+    type: level
+    parmas:
+      level: error
+`))
+		assert.ErrorIs(t, err, logger.ErrInvalidStage)
+		buildErr, ok := err.(*logger.BuildError)
+		if assert.True(t, ok) && assert.Equal(t, 1, len(buildErr.Errs)) {
+			assert.Equal(t, 1, buildErr.Errs[0].Index)
+			assert.Equal(t, `stages[1] "filter" (level): InvalidStage: line 6: unknown key "parmas"`, buildErr.Errs[0].Error())
+		}
+	})
+
+	t.Run("top level", func(t *testing.T) {
+		_, err := logger.ParsePipelineConfig([]byte(`{"stagse": [{"type": "prefix"}]}`))
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "stagse")
+		}
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		c, err := logger.ParsePipelineConfig(nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, len(c.Stages))
+	})
+}
@@ -0,0 +1,118 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+	"unsafe"
+)
+
+// eventBox holds an event and its extra data in one allocation, reused by eventPool.
+type eventBox struct {
+	ev    event
+	extra extraEvent
+}
+
+var eventPool = sync.Pool{
+	New: func() any {
+		b := &eventBox{}
+		b.extra.Event = &b.ev
+		return b
+	},
+}
+
+func getEvent(level Level, format string, args []any, extra Extra) *eventBox {
+	b := eventPool.Get().(*eventBox)
+	b.ev = event{
+		level:  level,
+		format: format,
+		args:   args,
+	}
+	b.extra.extra = extra
+	return b
+}
+
+func putEvent(b *eventBox) {
+	b.ev = event{}
+	b.extra.extra = Extra{}
+	eventPool.Put(b)
+}
+
+// maxPooledBuffer is the capacity limit of the buffers back to bufferPool,
+// not to keep the huge buffers.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+func getBuffer() *bytes.Buffer {
+	b := bufferPool.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+func putBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	bufferPool.Put(b)
+}
+
+// bytesToString converts b into a string without copying.
+// b must not be modified while the string is in use.
+func bytesToString(b []byte) string {
+	return *(*string)(unsafe.Pointer(&b))
+}
+
+// transientProxy is implemented by the proxies that never keep the events after Put,
+// so that the events can be reused.
+type transientProxy interface {
+	transient() bool
+}
+
+// sanitizedArgs is the args with the wrapped ones by `sanitizeArgs`, reused by sanitizedArgsPool
+// not to allocate the wrappers.
+type sanitizedArgs struct {
+	args    []any
+	wrapped []sanitizedArg
+}
+
+var sanitizedArgsPool = sync.Pool{
+	New: func() any { return &sanitizedArgs{} },
+}
+
+func getSanitizedArgs(format string, args []any) *sanitizedArgs {
+	a := sanitizedArgsPool.Get().(*sanitizedArgs)
+	if cap(a.wrapped) < len(args) {
+		a.wrapped = make([]sanitizedArg, len(args))
+	}
+	a.wrapped = a.wrapped[:len(args)]
+	a.args = append(a.args[:0], args...)
+	var (
+		raw    uint64
+		parsed bool
+	)
+	for i, x := range args {
+		if !needSanitizeArg(x) {
+			continue
+		}
+		if !parsed {
+			raw, parsed = rawArgs(format), true
+		}
+		if i >= 64 || raw&(1<<i) == 0 {
+			a.wrapped[i] = sanitizedArg{v: x}
+			a.args[i] = &a.wrapped[i]
+		}
+	}
+	return a
+}
+
+func putSanitizedArgs(a *sanitizedArgs) {
+	for i := range a.args {
+		a.args[i] = nil
+	}
+	for i := range a.wrapped {
+		a.wrapped[i] = sanitizedArg{}
+	}
+	sanitizedArgsPool.Put(a)
+}
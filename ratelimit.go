@@ -0,0 +1,254 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RateKeyFunc groups the events into the buckets of `RateLimiter`.
+type RateKeyFunc func(Event) string
+
+// RateKeyByLevel groups the events by the level.
+func RateKeyByLevel(ev Event) string { return ev.Level().String() }
+
+// RateKeyByFormat groups the events by the format.
+func RateKeyByFormat(ev Event) string { return ev.Format() }
+
+// RateKeyByLevelAndFormat groups the events by the level and the format.
+func RateKeyByLevelAndFormat(ev Event) string { return ev.Level().String() + " " + ev.Format() }
+
+// RateKeyByField groups the events by the value of the field.
+func RateKeyByField(key string) RateKeyFunc {
+	return func(ev Event) string {
+		for _, f := range ExtraOf(ev).Fields {
+			if f.Key == key {
+				return fmt.Sprintf("%s=%v", key, f.Value)
+			}
+		}
+		return key + "="
+	}
+}
+
+// RateLimiterConfig is the configuration of `RateLimiter`.
+type RateLimiterConfig struct {
+	// Rate is the number of the events per second allowed for each bucket.
+	Rate float64
+	// Burst is the size of the bucket.
+	Burst int
+	// Key groups the events into the buckets, default is `RateKeyByLevelAndFormat`.
+	Key RateKeyFunc
+	// Summarize enables the summary events of the dropped events.
+	Summarize bool
+	// SummaryInterval is the interval to emit the summaries.
+	// If 0, the summaries are emitted only by Flush.
+	SummaryInterval time.Duration
+}
+
+type rateBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed uint64
+	// level is the most severe level of the suppressed events
+	level Level
+}
+
+// RateLimiter drops the events over the rate by the token buckets.
+//
+// The summaries like "suppressed 4213 events matching X in last 10s" are passed
+// to the emit function, see `SetEmit`.
+// In the pipeline built by `Build`, emit passes the summaries to the following stages.
+type RateLimiter struct {
+	cfg RateLimiterConfig
+
+	mux     sync.Mutex
+	buckets map[string]*rateBucket
+	// sweepAt is the number of the buckets to drop the idle ones, see `sweep`
+	sweepAt int
+	since   time.Time
+	emit    func(Event)
+
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewRateLimiter returns a new RateLimiter.
+// If cfg.Summarize and cfg.SummaryInterval are set, this starts a goroutine to emit the summaries,
+// call Close to stop it.
+func NewRateLimiter(cfg RateLimiterConfig) *RateLimiter {
+	if cfg.Key == nil {
+		cfg.Key = RateKeyByLevelAndFormat
+	}
+	r := &RateLimiter{
+		cfg:     cfg,
+		buckets: map[string]*rateBucket{},
+		sweepAt: minRateSweep,
+		since:   time.Now(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	if cfg.Summarize && cfg.SummaryInterval > 0 {
+		go r.loop()
+	} else {
+		close(r.done)
+	}
+	return r
+}
+
+func (r *RateLimiter) loop() {
+	defer close(r.done)
+	ticker := time.NewTicker(r.cfg.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.summarize()
+		}
+	}
+}
+
+// SetEmit sets the destination of the summaries.
+func (r *RateLimiter) SetEmit(emit func(Event)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.emit = emit
+}
+
+// Mapper passes the event if the bucket has a token, otherwise returns nil.
+func (r *RateLimiter) Mapper(ev Event) (Event, error) {
+	var (
+		key = r.cfg.Key(ev)
+		now = time.Now()
+	)
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	b, ok := r.buckets[key]
+	if !ok {
+		if len(r.buckets) >= r.sweepAt {
+			r.sweep(now)
+		}
+		b = &rateBucket{
+			tokens: float64(r.cfg.Burst),
+			last:   now,
+		}
+		r.buckets[key] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * r.cfg.Rate
+	if burst := float64(r.cfg.Burst); b.tokens > burst {
+		b.tokens = burst
+	}
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return ev, nil
+	}
+	if b.suppressed == 0 || ev.Level() < b.level {
+		b.level = ev.Level()
+	}
+	b.suppressed++
+	return nil, nil
+}
+
+// minRateSweep is the least number of the buckets to sweep.
+const minRateSweep = 1024
+
+// sweep drops the idle buckets, and doubles the threshold of the next sweep by the rest,
+// so that the buckets by the keys with the unbounded variety like the user ids do not grow without limit.
+// The buckets with the suppressed events are kept for the summaries.
+func (r *RateLimiter) sweep(now time.Time) {
+	for k, b := range r.buckets {
+		if r.idle(b, now) && (b.suppressed == 0 || !r.cfg.Summarize) {
+			delete(r.buckets, k)
+		}
+	}
+	r.sweepAt = 2 * len(r.buckets)
+	if r.sweepAt < minRateSweep {
+		r.sweepAt = minRateSweep
+	}
+}
+
+// idle reports whether the bucket is full again, no need to keep.
+func (r *RateLimiter) idle(b *rateBucket, now time.Time) bool {
+	return b.tokens+now.Sub(b.last).Seconds()*r.cfg.Rate >= float64(r.cfg.Burst)
+}
+
+// Len returns the number of the buckets in use.
+func (r *RateLimiter) Len() int {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return len(r.buckets)
+}
+
+// summarize emits the summaries of the suppressed events and resets the counts.
+func (r *RateLimiter) summarize() {
+	var (
+		now = time.Now()
+		evs []Event
+	)
+	r.mux.Lock()
+	keys := make([]string, 0, len(r.buckets))
+	for k := range r.buckets {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		b := r.buckets[k]
+		if b.suppressed == 0 {
+			if r.idle(b, now) {
+				delete(r.buckets, k)
+			}
+			continue
+		}
+		if r.cfg.Summarize {
+			window := now.Sub(r.since).Round(time.Millisecond)
+			evs = append(evs, WithExtra(
+				NewEvent(b.level, "suppressed %d events matching %s in last %s", []any{b.suppressed, k, window}),
+				Extra{
+					Time: now,
+					Fields: []Field{
+						{Key: "suppressed", Value: b.suppressed},
+						{Key: "key", Value: k},
+					},
+				},
+			))
+		}
+		b.suppressed = 0
+	}
+	r.since = now
+	emit := r.emit
+	r.mux.Unlock()
+
+	if emit == nil {
+		return
+	}
+	for _, ev := range evs {
+		emit(ev)
+	}
+}
+
+// Flush emits the summaries.
+func (r *RateLimiter) Flush() error {
+	r.summarize()
+	return nil
+}
+
+// Close stops the summary goroutine and emits the last summaries.
+func (r *RateLimiter) Close() error {
+	r.once.Do(func() { close(r.stop) })
+	<-r.done
+	return r.Flush()
+}
+
+// Stage returns the stage of the pipeline.
+func (r *RateLimiter) Stage() *Stage {
+	return &Stage{
+		Mapper:  r.Mapper,
+		Flush:   r.Flush,
+		Close:   r.Close,
+		Connect: r.SetEmit,
+	}
+}
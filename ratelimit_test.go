@@ -0,0 +1,168 @@
+package logger_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	r := logger.NewRateLimiter(logger.RateLimiterConfig{
+		Rate:      0.001,
+		Burst:     2,
+		Key:       logger.RateKeyByField("host"),
+		Summarize: true,
+	})
+	var summaries []logger.Event
+	r.SetEmit(func(ev logger.Event) { summaries = append(summaries, ev) })
+
+	newEv := func(level logger.Level, host string) logger.Event {
+		return logger.AddFields(logger.NewEvent(level, "unreachable", nil), logger.Field{Key: "host", Value: host})
+	}
+	var passed int
+	for i := 0; i < 5; i++ {
+		for _, ev := range []logger.Event{
+			newEv(logger.Lwarn, "a"),
+			newEv(logger.Lerror, "a"),
+			newEv(logger.Lwarn, "b"),
+		} {
+			if got, _ := r.Mapper(ev); got != nil {
+				passed++
+			}
+		}
+	}
+	assert.Equal(t, 4, passed)
+
+	assert.Nil(t, r.Close())
+	if !assert.Equal(t, 2, len(summaries)) {
+		return
+	}
+	a := summaries[0]
+	assert.Equal(t, logger.Lerror, a.Level())
+	assert.True(t, strings.HasPrefix(fmt.Sprint(a), "suppressed 8 events matching host=a in last "), fmt.Sprint(a))
+	assert.Equal(t, []logger.Field{
+		{Key: "suppressed", Value: uint64(8)},
+		{Key: "key", Value: "host=a"},
+	}, logger.ExtraOf(a).Fields)
+	assert.Equal(t, logger.Lwarn, summaries[1].Level())
+	assert.True(t, strings.HasPrefix(fmt.Sprint(summaries[1]), "suppressed 3 events matching host=b"))
+}
+
+type syncBuffer struct {
+	mux sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mux.Lock()
+	defer b.mux.Unlock()
+	return b.buf.String()
+}
+
+func TestRateLimiterStage(t *testing.T) {
+	r := logger.NewRegistry()
+	var buf syncBuffer
+	assert.Nil(t, r.Register("buffer", func(_ *logger.Params) (*logger.Stage, error) {
+		return &logger.Stage{
+			Mapper: logger.NewWriterConsumer(&buf, logger.NewLogfmtEncoder(logger.EncoderConfig{})),
+		}, nil
+	}))
+	config, err := logger.ParsePipelineConfig([]byte(`
+stages:
+  - type: ratelimit
+    params:
+      rate: 0.001
+      burst: 1
+      summary_interval: 20ms
+  - type: buffer
+`))
+	assert.Nil(t, err)
+	p, err := r.Build(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer p.(interface{ Close() error }).Close()
+
+	l := &logger.Logger{Proxy: p}
+	for i := 0; i < 3; i++ {
+		l.Info("flood")
+	}
+	assert.Eventually(t, func() bool {
+		return strings.Contains(buf.String(), "suppressed=2 key=")
+	}, time.Second, 10*time.Millisecond)
+	assert.True(t, strings.HasPrefix(buf.String(), "level=info msg=flood\nlevel=info msg=\"suppressed 2 events matching info flood in last "), buf.String())
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	for _, summarize := range []bool{false, true} {
+		summarize := summarize
+		t.Run(fmt.Sprintf("summarize %v", summarize), func(t *testing.T) {
+			r := logger.NewRateLimiter(logger.RateLimiterConfig{
+				Rate:      1e9,
+				Burst:     1,
+				Key:       logger.RateKeyByField("user"),
+				Summarize: summarize,
+			})
+			defer r.Close()
+			for i := 0; i < 10000; i++ {
+				ev := logger.AddFields(logger.NewEvent(logger.Linfo, "login", nil), logger.Field{Key: "user", Value: i})
+				got, _ := r.Mapper(ev)
+				assert.NotNil(t, got)
+			}
+			assert.LessOrEqual(t, r.Len(), 2048)
+		})
+	}
+}
+
+func TestRateLimiterStageErrConsumer(t *testing.T) {
+	r := logger.NewRegistry()
+	assert.Nil(t, r.Register("fail", func(_ *logger.Params) (*logger.Stage, error) {
+		return &logger.Stage{
+			Mapper: func(ev logger.Event) (logger.Event, error) {
+				return nil, errors.New("fail")
+			},
+		}, nil
+	}))
+	config, err := logger.ParsePipelineConfig([]byte(`
+stages:
+  - type: ratelimit
+    params:
+      rate: 0.001
+      burst: 1
+      summary_interval: 1ms
+  - type: fail
+`))
+	assert.Nil(t, err)
+	p, err := r.Build(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	defer p.(interface{ Close() error }).Close()
+
+	var (
+		l     = &logger.Logger{Proxy: p}
+		count atomic.Int64
+	)
+	for i := 0; i < 20; i++ {
+		// the summaries report the errors from the goroutine of the rate limiter
+		l.SetErrConsumer(func(error) { count.Add(1) })
+		l.Info("flood")
+		l.Info("flood")
+		time.Sleep(time.Millisecond)
+	}
+	assert.Eventually(t, func() bool { return count.Load() >= 2 }, time.Second, 10*time.Millisecond)
+}
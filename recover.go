@@ -0,0 +1,80 @@
+package logger
+
+import (
+	"runtime"
+	"strings"
+	"time"
+)
+
+// Flush flushes the proxy if it implements `Flush() error`, like the pipelines by `Build`.
+func (l *Logger) Flush() error {
+	if x, ok := l.Proxy.(interface{ Flush() error }); ok {
+		return x.Flush()
+	}
+	return nil
+}
+
+// Close closes the proxy if it implements `Close() error`, like the pipelines by `Build`
+// and the loggers by `NewDefaultFromEnv` that write to a file.
+func (l *Logger) Close() error {
+	if x, ok := l.Proxy.(interface{ Close() error }); ok {
+		return x.Close()
+	}
+	return nil
+}
+
+// Recover logs the panic as an Lerror event with the stack trace, flushes the proxy,
+// and panics again if Repanic.
+// The stack trace is written by all of the formats including the default one of `NewDefault` and `G`.
+//
+//	defer l.Recover()
+func (l *Logger) Recover() {
+	if r := recover(); r != nil {
+		l.HandlePanic(r)
+	}
+}
+
+// Go calls f in a new goroutine, recovering the panic by `Recover`.
+func (l *Logger) Go(f func()) {
+	go func() {
+		defer l.Recover()
+		f()
+	}()
+}
+
+// HandlePanic is `Recover` for the value recovered by the caller,
+// for the functions that call recover by themselves.
+func (l *Logger) HandlePanic(r any) {
+	depth := 32
+	if l.Stack != nil {
+		depth = l.Stack.depth()
+	}
+	extra := Extra{
+		Time:  time.Now(),
+		Name:  l.Name,
+		Stack: panicStack(depth),
+	}
+	if err, ok := r.(error); ok {
+		extra.Err = err
+	}
+	l.Put(WithExtra(NewEvent(Lerror, "panic: %v", []any{r}), extra))
+	_ = l.Flush()
+	if l.Repanic {
+		panic(r)
+	}
+}
+
+// panicStack returns the frames from the function that panicked.
+func panicStack(depth int) []runtime.Frame {
+	frames := captureStack(2, depth+8)
+	for i, f := range frames {
+		if !strings.HasPrefix(f.Function, "runtime.") {
+			frames = frames[i:]
+			break
+		}
+	}
+	if len(frames) > depth {
+		frames = frames[:depth]
+	}
+	return frames
+}
@@ -0,0 +1,97 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type flushProxy struct {
+	logger.Proxy
+	flushed int
+}
+
+func (p *flushProxy) Flush() error {
+	p.flushed++
+	return nil
+}
+
+func TestRecover(t *testing.T) {
+	var (
+		got   []logger.Event
+		mux   sync.Mutex
+		proxy = &flushProxy{
+			Proxy: logger.NewProxy(logger.MustNewMapperFunc(func(ev logger.Event) {
+				mux.Lock()
+				defer mux.Unlock()
+				got = append(got, ev)
+			})),
+		}
+		l = &logger.Logger{Proxy: proxy}
+	)
+
+	t.Run("swallow", func(t *testing.T) {
+		got = nil
+		func() {
+			defer l.Recover()
+			panic("boom")
+		}()
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, logger.Lerror, got[0].Level())
+		assert.Equal(t, "panic: boom", fmt.Sprint(got[0]))
+		stack := logger.ExtraOf(got[0]).Stack
+		if assert.NotEmpty(t, stack) {
+			assert.True(t, strings.HasSuffix(stack[0].Function, "TestRecover.func2.1"), stack[0].Function)
+		}
+		assert.Equal(t, 1, proxy.flushed)
+	})
+
+	t.Run("repanic", func(t *testing.T) {
+		got = nil
+		err := errors.New("boom")
+		l := &logger.Logger{Proxy: proxy, Repanic: true}
+		assert.PanicsWithValue(t, err, func() {
+			defer l.Recover()
+			panic(err)
+		})
+		assert.Equal(t, 1, len(got))
+		assert.Equal(t, err, logger.ErrorOf(got[0]))
+	})
+
+	t.Run("go", func(t *testing.T) {
+		done := make(chan logger.Event, 1)
+		l := &logger.Logger{
+			Proxy: logger.NewProxy(logger.MustNewMapperFunc(func(ev logger.Event) { done <- ev })),
+		}
+		l.Go(func() {
+			panic("in goroutine")
+		})
+		assert.Equal(t, "panic: in goroutine", fmt.Sprint(<-done))
+	})
+
+	t.Run("no panic", func(t *testing.T) {
+		got = nil
+		func() {
+			defer l.Recover()
+		}()
+		assert.Empty(t, got)
+	})
+}
+
+func TestRecoverDefault(t *testing.T) {
+	l := logger.NewDefault(logger.Linfo)
+	got := captureStandardLog(func() {
+		defer l.Recover()
+		panic("boom")
+	})
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	if assert.Greater(t, len(lines), 1, got) {
+		assert.Equal(t, "E | panic: boom", lines[0])
+		assert.True(t, strings.HasPrefix(lines[1], "    at github.com/berquerant/logger_test.TestRecoverDefault.func1 ("), lines[1])
+	}
+}
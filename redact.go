@@ -0,0 +1,328 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+// Redactor is implemented by the values that hide themselves in the logs.
+// The redaction replaces the value with the result of Redact.
+type Redactor interface {
+	Redact() any
+}
+
+// RedactTag is the struct tag to redact the field, like `redact:"true"`.
+const RedactTag = "redact"
+
+// RedactMode is how to mask the values.
+type RedactMode int
+
+const (
+	// RedactReplace replaces the value with the replacement.
+	RedactReplace RedactMode = iota
+	// RedactPartial masks the value except the last characters, like "************1234".
+	RedactPartial
+	// RedactHash replaces the value with the keyed hash (HMAC-SHA256), to correlate the values without revealing them.
+	RedactHash
+)
+
+// Builtin patterns for `RedactConfig.Patterns`.
+var (
+	RedactPatternEmail  = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9.\-]+\.[a-zA-Z]{2,}`)
+	RedactPatternCard   = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+	RedactPatternBearer = regexp.MustCompile(`(?i)bearer\s+[a-zA-Z0-9._~+/\-]+=*`)
+)
+
+// RedactConfig is the configuration of `Redaction`.
+type RedactConfig struct {
+	// Keys are the field keys or the map keys to redact, case-insensitive, like "password".
+	Keys []string
+	// Patterns are the regular expressions to redact from the formatted message and the string values.
+	Patterns []*regexp.Regexp
+	Mode     RedactMode
+	// Replacement is used by RedactReplace, default is "[REDACTED]".
+	Replacement string
+	// Keep is the number of the last characters RedactPartial leaves, default is 4.
+	Keep int
+	// HashKey is the key of RedactHash, required by RedactHash.
+	HashKey []byte
+}
+
+var ErrInvalidRedactConfig = errors.New("InvalidRedactConfig")
+
+// Redaction masks the secrets and the personal data in the events
+// by the key names, by the patterns and by the Go types, see `Redactor` and `RedactTag`.
+//
+// Put this before any encoder.
+type Redaction struct {
+	cfg  RedactConfig
+	keys map[string]bool
+}
+
+func NewRedaction(cfg RedactConfig) (*Redaction, error) {
+	if cfg.Replacement == "" {
+		cfg.Replacement = "[REDACTED]"
+	}
+	if cfg.Keep <= 0 {
+		cfg.Keep = 4
+	}
+	switch cfg.Mode {
+	case RedactReplace, RedactPartial:
+	case RedactHash:
+		if len(cfg.HashKey) == 0 {
+			return nil, fmt.Errorf("%w: hash mode requires the key", ErrInvalidRedactConfig)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown mode %d", ErrInvalidRedactConfig, cfg.Mode)
+	}
+	keys := make(map[string]bool, len(cfg.Keys))
+	for _, k := range cfg.Keys {
+		keys[strings.ToLower(k)] = true
+	}
+	return &Redaction{
+		cfg:  cfg,
+		keys: keys,
+	}, nil
+}
+
+// Mapper redacts the fields, the args, the formatted message and the attached error of the event.
+func (r *Redaction) Mapper(ev Event) (Event, error) {
+	var (
+		extra   = ExtraOf(ev)
+		args    = ev.Args()
+		changed bool
+	)
+	if len(extra.Fields) > 0 {
+		fields := make([]Field, len(extra.Fields))
+		for i, f := range extra.Fields {
+			v, ok := r.value(f.Key, f.Value, 0)
+			fields[i] = Field{
+				Key:   f.Key,
+				Value: v,
+			}
+			changed = changed || ok
+		}
+		extra.Fields = fields
+	}
+	if len(args) > 0 {
+		xs := make([]any, len(args))
+		for i, a := range args {
+			v, ok := r.value("", a, 0)
+			xs[i] = v
+			changed = changed || ok
+		}
+		args = xs
+	}
+	if extra.Err != nil {
+		if err, ok := r.redactError(extra.Err, 0); ok {
+			extra.Err = err
+			changed = true
+		}
+	}
+
+	format := ev.Format()
+	if len(r.cfg.Patterns) > 0 {
+		if s, ok := r.patterns(fmt.Sprintf(format, args...)); ok {
+			format = "%s"
+			args = []any{s}
+			changed = true
+		}
+	}
+	if !changed {
+		return ev, nil
+	}
+	return WithExtra(DeriveEvent(ev, ev.Level(), format, args), extra), nil
+}
+
+// redactError returns the copy of the unwrap tree of err with the messages redacted by the patterns.
+func (r *Redaction) redactError(err error, depth int) (error, bool) {
+	msg, changed := r.patterns(err.Error())
+	var causes []error
+	if depth < errorMaxDepth {
+		for _, e := range unwrapErrors(err) {
+			if e == nil {
+				continue
+			}
+			x, ok := r.redactError(e, depth+1)
+			causes = append(causes, x)
+			changed = changed || ok
+		}
+	}
+	if !changed {
+		return err, false
+	}
+	return &redactedError{
+		err:    err,
+		msg:    msg,
+		causes: causes,
+	}, true
+}
+
+// redactedError is an error with the redacted message and causes.
+// errors.Is and errors.As see the original error, and the encoders write the type of the original.
+type redactedError struct {
+	err    error
+	msg    string
+	causes []error
+}
+
+func (e *redactedError) Error() string        { return e.msg }
+func (e *redactedError) Unwrap() []error      { return e.causes }
+func (e *redactedError) Is(target error) bool { return errors.Is(e.err, target) }
+func (e *redactedError) As(target any) bool   { return errors.As(e.err, target) }
+
+// Value returns the redacted value.
+// key is the field key or the map key of the value, may be empty.
+func (r *Redaction) Value(key string, v any) any {
+	x, _ := r.value(key, v, 0)
+	return x
+}
+
+// redactMaxDepth limits the traversal of the nested values, against the cyclic references.
+const redactMaxDepth = 8
+
+// value returns the redacted value and true if redacted.
+func (r *Redaction) value(key string, v any, depth int) (any, bool) {
+	if key != "" && r.keys[strings.ToLower(key)] {
+		return r.mask(fmt.Sprint(v)), true
+	}
+	if depth > redactMaxDepth {
+		return v, false
+	}
+	switch x := v.(type) {
+	case nil:
+		return nil, false
+	case Redactor:
+		return x.Redact(), true
+	case string:
+		return r.patterns(x)
+	case []byte:
+		if s, ok := r.patterns(string(x)); ok {
+			return s, true
+		}
+		return x, false
+	case error, fmt.Stringer:
+		return v, false
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v, false
+		}
+		var (
+			m       = make(map[string]any, rv.Len())
+			changed bool
+			iter    = rv.MapRange()
+		)
+		for iter.Next() {
+			x, ok := r.value(iter.Key().String(), iter.Value().Interface(), depth+1)
+			m[iter.Key().String()] = x
+			changed = changed || ok
+		}
+		if !changed {
+			return v, false
+		}
+		return m, true
+	case reflect.Slice, reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v, false
+		}
+		var (
+			xs      = make([]any, rv.Len())
+			changed bool
+		)
+		for i := range xs {
+			x, ok := r.value("", rv.Index(i).Interface(), depth+1)
+			xs[i] = x
+			changed = changed || ok
+		}
+		if !changed {
+			return v, false
+		}
+		return xs, true
+	case reflect.Pointer:
+		if rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+			return v, false
+		}
+		return r.structValue(v, rv.Elem(), depth)
+	case reflect.Struct:
+		return r.structValue(v, rv, depth)
+	default:
+		return v, false
+	}
+}
+
+// structValue converts the struct into a map keyed by the JSON names if any field is redacted.
+func (r *Redaction) structValue(v any, rv reflect.Value, depth int) (any, bool) {
+	var (
+		t       = rv.Type()
+		m       = make(map[string]any, t.NumField())
+		changed bool
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			n, _, _ := strings.Cut(tag, ",")
+			if n == "-" {
+				continue
+			}
+			if n != "" {
+				name = n
+			}
+		}
+		fv := rv.Field(i).Interface()
+		if tag, ok := f.Tag.Lookup(RedactTag); ok && tag != "false" && tag != "-" {
+			m[name] = r.mask(fmt.Sprint(fv))
+			changed = true
+			continue
+		}
+		x, ok := r.value(name, fv, depth+1)
+		m[name] = x
+		changed = changed || ok
+	}
+	if !changed {
+		return v, false
+	}
+	return m, true
+}
+
+// patterns masks the matched parts of s.
+func (r *Redaction) patterns(s string) (string, bool) {
+	var changed bool
+	for _, p := range r.cfg.Patterns {
+		s = p.ReplaceAllStringFunc(s, func(x string) string {
+			changed = true
+			return r.mask(x)
+		})
+	}
+	return s, changed
+}
+
+func (r *Redaction) mask(s string) string {
+	switch r.cfg.Mode {
+	case RedactPartial:
+		rs := []rune(s)
+		if len(rs) <= r.cfg.Keep {
+			return strings.Repeat("*", len(rs))
+		}
+		return strings.Repeat("*", len(rs)-r.cfg.Keep) + string(rs[len(rs)-r.cfg.Keep:])
+	case RedactHash:
+		h := hmac.New(sha256.New, r.cfg.HashKey)
+		h.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil))[:16]
+	default:
+		return r.cfg.Replacement
+	}
+}
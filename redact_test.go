@@ -0,0 +1,126 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+type apiToken string
+
+func (apiToken) Redact() any { return "token(***)" }
+
+type user struct {
+	Name     string `json:"name"`
+	Password string `json:"password"`
+	Card     string `json:"card" redact:"true"`
+}
+
+func TestRedaction(t *testing.T) {
+	ev := logger.AddFields(
+		logger.NewEvent(logger.Linfo, "login %s by %v with %v", []any{
+			"alice@example.com",
+			apiToken("abc"),
+			user{Name: "alice", Password: "secret", Card: "4111111111111111"},
+		}),
+		logger.Field{Key: "Authorization", Value: "Bearer xyz"},
+		logger.Field{Key: "header", Value: map[string]any{"password": "p", "accept": "*/*"}},
+		logger.Field{Key: "count", Value: 1},
+	)
+
+	t.Run("replace", func(t *testing.T) {
+		r, err := logger.NewRedaction(logger.RedactConfig{
+			Keys:     []string{"authorization", "password"},
+			Patterns: []*regexp.Regexp{logger.RedactPatternEmail},
+		})
+		assert.Nil(t, err)
+		got, err := r.Mapper(ev)
+		assert.Nil(t, err)
+		assert.Equal(t,
+			"login [REDACTED] by token(***) with map[card:[REDACTED] name:alice password:[REDACTED]]",
+			fmt.Sprint(got),
+		)
+		assert.Equal(t, []logger.Field{
+			{Key: "Authorization", Value: "[REDACTED]"},
+			{Key: "header", Value: map[string]any{"password": "[REDACTED]", "accept": "*/*"}},
+			{Key: "count", Value: 1},
+		}, logger.ExtraOf(got).Fields)
+	})
+
+	t.Run("partial", func(t *testing.T) {
+		r, err := logger.NewRedaction(logger.RedactConfig{
+			Patterns: []*regexp.Regexp{logger.RedactPatternCard},
+			Mode:     logger.RedactPartial,
+		})
+		assert.Nil(t, err)
+		got, err := r.Mapper(logger.NewEvent(logger.Linfo, "card %s", []any{"4111 1111 1111 1111"}))
+		assert.Nil(t, err)
+		assert.Equal(t, "card ***************1111", fmt.Sprint(got))
+	})
+
+	t.Run("hash", func(t *testing.T) {
+		_, err := logger.NewRedaction(logger.RedactConfig{Mode: logger.RedactHash})
+		assert.ErrorIs(t, err, logger.ErrInvalidRedactConfig)
+
+		r, err := logger.NewRedaction(logger.RedactConfig{
+			Keys:    []string{"email"},
+			Mode:    logger.RedactHash,
+			HashKey: []byte("key"),
+		})
+		assert.Nil(t, err)
+		a := r.Value("email", "alice@example.com")
+		b := r.Value("email", "bob@example.com")
+		assert.Equal(t, a, r.Value("email", "alice@example.com"))
+		assert.NotEqual(t, a, b)
+		assert.Regexp(t, `^hmac:[0-9a-f]{16}$`, a)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		r, err := logger.NewRedaction(logger.RedactConfig{Keys: []string{"password"}})
+		assert.Nil(t, err)
+		ev := logger.NewEvent(logger.Linfo, "hello %s", []any{"world"})
+		got, err := r.Mapper(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, ev, got)
+	})
+}
+
+func TestRedactionError(t *testing.T) {
+	r, err := logger.NewRedaction(logger.RedactConfig{
+		Patterns: []*regexp.Regexp{logger.RedactPatternBearer},
+	})
+	assert.Nil(t, err)
+	enc, err := logger.NewEncoder(logger.FormatJSON, logger.EncoderConfig{})
+	assert.Nil(t, err)
+
+	var got string
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.MustNewMapperFunc(r.Mapper).Next(func(ev logger.Event) error {
+			b, err := enc(ev)
+			got = string(b)
+			return err
+		})),
+	}
+	base := errors.New("auth Bearer abc.def.secret rejected")
+	cause := fmt.Errorf("call: %w", base)
+	l.Error("call failed: %v", cause)
+	assert.Equal(t, `{"level":"error","msg":"call failed: call: auth [REDACTED] rejected","error":{"msg":"call: auth [REDACTED] rejected","type":"*fmt.wrapError","causes":[{"msg":"auth [REDACTED] rejected","type":"*errors.errorString"}]}}`, got)
+
+	t.Run("keep the original for errors.Is", func(t *testing.T) {
+		ev, err := r.Mapper(logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), cause))
+		assert.Nil(t, err)
+		assert.ErrorIs(t, logger.ErrorOf(ev), base)
+		assert.NotContains(t, logger.ErrorOf(ev).Error(), "secret")
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		plain := errors.New("not found")
+		ev, err := r.Mapper(logger.WithError(logger.NewEvent(logger.Lerror, "failed", nil), plain))
+		assert.Nil(t, err)
+		assert.Equal(t, plain, logger.ErrorOf(ev))
+	})
+}
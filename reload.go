@@ -0,0 +1,172 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Reloader is a Proxy that builds the pipeline from the config file and rebuilds it on demand.
+//
+// Reload swaps the pipeline after the in-flight events of the old one are consumed,
+// and then flushes and closes the old one, so no events are lost.
+// If the new config is invalid, the old pipeline stays in place.
+type Reloader struct {
+	registry    *Registry
+	path        string
+	mux         sync.RWMutex
+	current     *pipeline
+	errConsumer func(error)
+}
+
+// NewReloader returns a new Reloader with the pipeline built from the config file by registry.
+// Use `DefaultRegistry` if registry is nil.
+func NewReloader(registry *Registry, path string) (*Reloader, error) {
+	if registry == nil {
+		registry = DefaultRegistry
+	}
+	r := &Reloader{
+		registry: registry,
+		path:     path,
+	}
+	p, err := r.build()
+	if err != nil {
+		return nil, err
+	}
+	r.current = p
+	return r, nil
+}
+
+func (r *Reloader) build() (*pipeline, error) {
+	config, err := ReadPipelineConfigFile(r.path)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := r.registry.BuildStages(config)
+	if err != nil {
+		return nil, err
+	}
+	return newPipeline(stages), nil
+}
+
+func (r *Reloader) Put(ev Event) {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	r.current.Put(ev)
+}
+
+// Enabled reports `LevelEnabler` of the current pipeline.
+func (r *Reloader) Enabled(level Level) bool {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.current.Enabled(level)
+}
+
+func (r *Reloader) SetErrConsumer(errConsumer func(error)) {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	r.errConsumer = errConsumer
+	r.current.SetErrConsumer(errConsumer)
+}
+
+func (r *Reloader) consumeErr(err error) {
+	r.mux.RLock()
+	f := r.errConsumer
+	r.mux.RUnlock()
+	if f != nil {
+		f(err)
+	}
+}
+
+// Reload rebuilds the pipeline from the config file.
+// If it fails, the error is also reported to the err consumer.
+func (r *Reloader) Reload() error {
+	next, err := r.build()
+	if err != nil {
+		err = fmt.Errorf("reload %s: %w", r.path, err)
+		r.consumeErr(err)
+		return err
+	}
+
+	r.mux.Lock()
+	prev := r.current
+	next.SetErrConsumer(r.errConsumer)
+	r.current = next
+	r.mux.Unlock()
+
+	if err := prev.Close(); err != nil {
+		err = fmt.Errorf("close the previous pipeline: %w", err)
+		r.consumeErr(err)
+		return err
+	}
+	return nil
+}
+
+func (r *Reloader) Flush() error {
+	r.mux.RLock()
+	defer r.mux.RUnlock()
+	return r.current.Flush()
+}
+
+func (r *Reloader) Close() error {
+	r.mux.Lock()
+	defer r.mux.Unlock()
+	return r.current.Close()
+}
+
+// WatchSignal reloads the pipeline when the process receives the signals until ctx is done.
+// The default signal is SIGHUP.
+// On the platforms without SIGHUP like js/wasm, the signals are required, otherwise this returns immediately.
+func (r *Reloader) WatchSignal(ctx context.Context, sig ...os.Signal) {
+	if len(sig) == 0 {
+		sig = defaultReloadSignals
+	}
+	if len(sig) == 0 {
+		// signal.Notify without the signals relays all of them
+		return
+	}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, sig...)
+	defer signal.Stop(c)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-c:
+			_ = r.Reload()
+		}
+	}
+}
+
+// WatchFile reloads the pipeline when the config file changes until ctx is done.
+// This checks the modification time and the size of the file every interval.
+func (r *Reloader) WatchFile(ctx context.Context, interval time.Duration) {
+	stat := func() (time.Time, int64, bool) {
+		fi, err := os.Stat(r.path)
+		if err != nil {
+			return time.Time{}, 0, false
+		}
+		return fi.ModTime(), fi.Size(), true
+	}
+	modTime, size, _ := stat()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m, s, ok := stat()
+			if !ok || (m.Equal(modTime) && s == size) {
+				continue
+			}
+			modTime, size = m, s
+			_ = r.Reload()
+		}
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package logger
+
+import "os"
+
+// defaultReloadSignals is empty since the platform has no SIGHUP,
+// `Reloader.WatchSignal` requires the signals.
+var defaultReloadSignals []os.Signal
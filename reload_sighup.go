@@ -0,0 +1,11 @@
+//go:build unix || windows
+
+package logger
+
+import (
+	"os"
+	"syscall"
+)
+
+// defaultReloadSignals are the signals of `Reloader.WatchSignal` without the arguments.
+var defaultReloadSignals = []os.Signal{syscall.SIGHUP}
@@ -0,0 +1,102 @@
+package logger_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloader(t *testing.T) {
+	var (
+		dir         = t.TempDir()
+		configPath  = filepath.Join(dir, "pipeline.yml")
+		outPath     = filepath.Join(dir, "out.log")
+		writeConfig = func(t *testing.T, format string) {
+			assert.Nil(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`
+stages:
+  - type: writer
+    params:
+      output: %s
+      format: %s
+`, outPath, format)), 0o600))
+		}
+		readOut = func(t *testing.T) string {
+			b, err := os.ReadFile(outPath)
+			assert.Nil(t, err)
+			return string(b)
+		}
+	)
+
+	writeConfig(t, "logfmt")
+	r, err := logger.NewReloader(nil, configPath)
+	if !assert.Nil(t, err) {
+		return
+	}
+	var errs []error
+	r.SetErrConsumer(func(err error) { errs = append(errs, err) })
+	l := &logger.Logger{Proxy: r}
+
+	l.Info("first")
+	writeConfig(t, "json")
+	assert.Nil(t, r.Reload())
+	l.Info("second")
+
+	writeConfig(t, "xml")
+	assert.ErrorIs(t, r.Reload(), logger.ErrInvalidFormat)
+	l.Info("third")
+
+	assert.Nil(t, r.Close())
+	if assert.Equal(t, 1, len(errs)) {
+		assert.ErrorIs(t, errs[0], logger.ErrInvalidFormat)
+	}
+	assert.Equal(t, `level=info msg=first
+{"level":"info","msg":"second"}
+{"level":"info","msg":"third"}
+`, readOut(t))
+}
+
+func TestReloaderWatchFile(t *testing.T) {
+	var (
+		dir        = t.TempDir()
+		configPath = filepath.Join(dir, "pipeline.json")
+		outPath    = filepath.Join(dir, "out.log")
+	)
+	writeConfig := func(level string) {
+		assert.Nil(t, os.WriteFile(configPath, []byte(fmt.Sprintf(`{"stages": [
+  {"type": "level", "params": {"level": %q}},
+  {"type": "writer", "params": {"output": %q, "format": "logfmt"}}
+]}`, level, outPath)), 0o600))
+	}
+
+	writeConfig("error")
+	r, err := logger.NewReloader(nil, configPath)
+	if !assert.Nil(t, err) {
+		return
+	}
+	l := &logger.Logger{Proxy: r}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.WatchFile(ctx, 10*time.Millisecond)
+	}()
+
+	l.Info("ignored")
+	time.Sleep(30 * time.Millisecond)
+	writeConfig("info")
+	assert.Eventually(t, func() bool {
+		l.Info("accepted")
+		b, _ := os.ReadFile(outPath)
+		return len(b) > 0
+	}, time.Second, 20*time.Millisecond)
+	cancel()
+	<-done
+	assert.Nil(t, r.Close())
+}
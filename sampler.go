@@ -0,0 +1,157 @@
+package logger
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SamplerConfig is the configuration common to the samplers.
+type SamplerConfig struct {
+	// KeepLevel makes the events of the level or lower always pass, like Lerror.
+	// Lsilent keeps nothing special.
+	KeepLevel Level
+	// ReportKey is the field key of the number of the dropped events.
+	// If not empty, the next passed event of the same level and format carries the number.
+	// The numbers are kept for up to `maxSamplerPending` groups, the others are counted only by `Sampler.Stats`.
+	ReportKey string
+	// Now returns the current time of the intervals, default is time.Now.
+	Now func() time.Time
+}
+
+func (c SamplerConfig) now() time.Time {
+	if c.Now == nil {
+		return time.Now()
+	}
+	return c.Now()
+}
+
+// SamplerStats is the counts of the sampler.
+type SamplerStats struct {
+	Passed  uint64
+	Dropped uint64
+}
+
+// maxSamplerPending limits the groups waiting for the report of the dropped events,
+// against the formats with the unbounded variety like the preformatted messages.
+const maxSamplerPending = 1024
+
+type samplerKey struct {
+	level  Level
+	format string
+}
+
+// Sampler drops a part of the events.
+// The events are grouped by the level and the format.
+type Sampler struct {
+	cfg     SamplerConfig
+	sample  func(key samplerKey) bool
+	passed  atomic.Uint64
+	dropped atomic.Uint64
+
+	mux     sync.Mutex
+	pending map[samplerKey]uint64
+}
+
+func newSampler(cfg SamplerConfig, sample func(key samplerKey) bool) *Sampler {
+	return &Sampler{
+		cfg:     cfg,
+		sample:  sample,
+		pending: map[samplerKey]uint64{},
+	}
+}
+
+type burstWindow struct {
+	start time.Time
+	count int
+}
+
+// NewBurstSampler returns a sampler that passes the first events per interval,
+// and then every thereafter-th event in the interval.
+// thereafter 0 drops all of the events after the first events.
+// The expired windows are dropped once per interval.
+func NewBurstSampler(first, thereafter int, interval time.Duration, cfg SamplerConfig) *Sampler {
+	var (
+		mux       sync.Mutex
+		windows   = map[samplerKey]*burstWindow{}
+		lastSweep = cfg.now()
+	)
+	return newSampler(cfg, func(key samplerKey) bool {
+		now := cfg.now()
+		mux.Lock()
+		defer mux.Unlock()
+		if now.Sub(lastSweep) >= interval {
+			for k, w := range windows {
+				if now.Sub(w.start) >= interval {
+					delete(windows, k)
+				}
+			}
+			lastSweep = now
+		}
+		w, ok := windows[key]
+		if !ok || now.Sub(w.start) >= interval {
+			w = &burstWindow{start: now}
+			windows[key] = w
+		}
+		w.count++
+		if w.count <= first {
+			return true
+		}
+		return thereafter > 0 && (w.count-first)%thereafter == 0
+	})
+}
+
+// NewRandomSampler returns a sampler that passes the events with the probability rate, from 0 to 1.
+func NewRandomSampler(rate float64, cfg SamplerConfig) *Sampler {
+	return newSampler(cfg, func(_ samplerKey) bool {
+		return rand.Float64() < rate
+	})
+}
+
+// Mapper passes or drops the event, returns nil if the event is dropped.
+func (s *Sampler) Mapper(ev Event) (Event, error) {
+	if ev.Level() <= s.cfg.KeepLevel {
+		s.passed.Add(1)
+		return ev, nil
+	}
+	key := samplerKey{
+		level:  ev.Level(),
+		format: ev.Format(),
+	}
+	if !s.sample(key) {
+		s.dropped.Add(1)
+		if s.cfg.ReportKey != "" {
+			s.mux.Lock()
+			if _, ok := s.pending[key]; ok || len(s.pending) < maxSamplerPending {
+				s.pending[key]++
+			}
+			s.mux.Unlock()
+		}
+		return nil, nil
+	}
+
+	s.passed.Add(1)
+	if s.cfg.ReportKey == "" {
+		return ev, nil
+	}
+	s.mux.Lock()
+	n := s.pending[key]
+	delete(s.pending, key)
+	s.mux.Unlock()
+	if n == 0 {
+		return ev, nil
+	}
+	return AddFields(ev, Field{
+		Key:   s.cfg.ReportKey,
+		Value: n,
+	}), nil
+}
+
+// Stats returns the counts of the passed and the dropped events.
+func (s *Sampler) Stats() SamplerStats {
+	return SamplerStats{
+		Passed:  s.passed.Load(),
+		Dropped: s.dropped.Load(),
+	}
+}
@@ -0,0 +1,111 @@
+package logger_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBurstSampler(t *testing.T) {
+	s := logger.NewBurstSampler(2, 3, time.Hour, logger.SamplerConfig{
+		KeepLevel: logger.Lerror,
+		ReportKey: "dropped",
+	})
+
+	var passed []int
+	for i := 1; i <= 10; i++ {
+		got, err := s.Mapper(logger.NewEvent(logger.Ldebug, "loop %d", []any{i}))
+		assert.Nil(t, err)
+		if got == nil {
+			continue
+		}
+		passed = append(passed, i)
+		if i == 5 {
+			assert.Equal(t, []logger.Field{{Key: "dropped", Value: uint64(2)}}, logger.ExtraOf(got).Fields)
+		}
+	}
+	assert.Equal(t, []int{1, 2, 5, 8}, passed)
+
+	t.Run("another key", func(t *testing.T) {
+		got, _ := s.Mapper(logger.NewEvent(logger.Ldebug, "other", nil))
+		assert.NotNil(t, got)
+		got, _ = s.Mapper(logger.NewEvent(logger.Linfo, "loop %d", []any{0}))
+		assert.NotNil(t, got)
+	})
+
+	t.Run("keep errors", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			got, _ := s.Mapper(logger.NewEvent(logger.Lerror, "failed", nil))
+			assert.NotNil(t, got)
+		}
+	})
+
+	assert.Equal(t, logger.SamplerStats{Passed: 11, Dropped: 6}, s.Stats())
+}
+
+func TestBurstSamplerInterval(t *testing.T) {
+	var clock fakeClock
+	s := logger.NewBurstSampler(1, 0, 20*time.Millisecond, logger.SamplerConfig{Now: clock.now})
+	ev := logger.NewEvent(logger.Linfo, "tick", nil)
+	got, _ := s.Mapper(ev)
+	assert.NotNil(t, got)
+	clock.add(19 * time.Millisecond)
+	got, _ = s.Mapper(ev)
+	assert.Nil(t, got)
+	clock.add(time.Millisecond)
+	got, _ = s.Mapper(ev)
+	assert.NotNil(t, got)
+}
+
+// fakeClock is the time driven by the tests.
+type fakeClock struct {
+	t time.Duration
+}
+
+func (c *fakeClock) now() time.Time      { return time.Unix(0, 0).Add(c.t) }
+func (c *fakeClock) add(d time.Duration) { c.t += d }
+
+func TestSamplerPendingLimit(t *testing.T) {
+	var clock fakeClock
+	s := logger.NewBurstSampler(1, 0, time.Second, logger.SamplerConfig{
+		ReportKey: "dropped",
+		Now:       clock.now,
+	})
+	const n = 2000
+	for i := 0; i < n; i++ {
+		ev := logger.NewEvent(logger.Linfo, fmt.Sprintf("message %d", i), nil)
+		_, _ = s.Mapper(ev)
+		got, _ := s.Mapper(ev)
+		assert.Nil(t, got)
+	}
+	clock.add(time.Second)
+	var reported int
+	for i := 0; i < n; i++ {
+		got, _ := s.Mapper(logger.NewEvent(logger.Linfo, fmt.Sprintf("message %d", i), nil))
+		if assert.NotNil(t, got) && len(logger.ExtraOf(got).Fields) > 0 {
+			reported++
+		}
+	}
+	assert.Equal(t, 1024, reported)
+	assert.Equal(t, logger.SamplerStats{Passed: 2 * n, Dropped: n}, s.Stats())
+}
+
+func TestRandomSampler(t *testing.T) {
+	ev := logger.NewEvent(logger.Linfo, "msg", nil)
+	for _, tc := range []struct {
+		rate float64
+		want logger.SamplerStats
+	}{
+		{rate: 0, want: logger.SamplerStats{Dropped: 100}},
+		{rate: 1, want: logger.SamplerStats{Passed: 100}},
+	} {
+		s := logger.NewRandomSampler(tc.rate, logger.SamplerConfig{})
+		for i := 0; i < 100; i++ {
+			_, _ = s.Mapper(ev)
+		}
+		assert.Equal(t, tc.want, s.Stats())
+	}
+}
@@ -0,0 +1,257 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Sanitize escapes the characters that can forge or corrupt log lines.
+//
+// The escapes are the same as the Go string literals:
+//
+//	\     -> \\
+//	CR LF -> \r \n
+//	TAB   -> \t
+//	other control characters including ESC of the ANSI escape sequences -> \xNN or \uNNNN
+//	line and paragraph separators, bidirectional overrides -> \uNNNN
+//	invalid UTF-8 bytes -> \xNN
+//
+// `Unsanitize` reverses it.
+func Sanitize(s string) string {
+	if !needSanitize(s) {
+		return s
+	}
+	var b strings.Builder
+	b.Grow(len(s) + 8)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			fmt.Fprintf(&b, `\x%02x`, s[i])
+		case r == '\\':
+			b.WriteString(`\\`)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x80 && unsafeRune(r):
+			fmt.Fprintf(&b, `\x%02x`, r)
+		case unsafeRune(r):
+			fmt.Fprintf(&b, `\u%04x`, r)
+		default:
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
+func needSanitize(s string) bool {
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if (r == utf8.RuneError && size == 1) || r == '\\' || unsafeRune(r) {
+			return true
+		}
+		i += size
+	}
+	return false
+}
+
+func unsafeRune(r rune) bool {
+	switch {
+	case r < 0x20, r == 0x7f, r >= 0x80 && r < 0xa0:
+		return true
+	case r == '\u2028', r == '\u2029':
+		return true
+	case r >= '\u202a' && r <= '\u202e', r >= '\u2066' && r <= '\u2069':
+		return true
+	default:
+		return false
+	}
+}
+
+var ErrInvalidSanitized = errors.New("InvalidSanitized")
+
+// Unsanitize reverses `Sanitize`.
+func Unsanitize(s string) (string, error) {
+	if !strings.Contains(s, `\`) {
+		return s, nil
+	}
+	var b strings.Builder
+	for len(s) > 0 {
+		if s[0] != '\\' {
+			i := strings.IndexByte(s, '\\')
+			if i < 0 {
+				i = len(s)
+			}
+			b.WriteString(s[:i])
+			s = s[i:]
+			continue
+		}
+		value, multibyte, tail, err := strconv.UnquoteChar(s, 0)
+		if err != nil {
+			return "", fmt.Errorf("%w: at %q", ErrInvalidSanitized, s)
+		}
+		if multibyte {
+			b.WriteRune(value)
+		} else {
+			b.WriteByte(byte(value))
+		}
+		s = tail
+	}
+	return b.String(), nil
+}
+
+// sanitizedArg formats the value and sanitizes the result, keeping the verb and the flags.
+type sanitizedArg struct {
+	v any
+}
+
+func (a sanitizedArg) Format(f fmt.State, verb rune) {
+	_, _ = f.Write([]byte(Sanitize(fmt.Sprintf(formatDirective(f, verb), a.v))))
+}
+
+// rawArgs returns the bit set of the args that fmt reads without formatting,
+// by %T, %p and * of the width and the precision, they must not be wrapped.
+// The args also formatted by the other verbs are not reported, escaping wins.
+// The args after the 64th are not reported.
+func rawArgs(format string) uint64 {
+	var (
+		set       uint64
+		formatted uint64
+		arg       int
+		mark      = func(s *uint64) {
+			if arg < 64 {
+				*s |= 1 << arg
+			}
+			arg++
+		}
+		index = func(i int) int {
+			// explicit argument index like %[2]d
+			if i < len(format) && format[i] == '[' {
+				end := strings.IndexByte(format[i:], ']')
+				if end < 0 {
+					return i
+				}
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil && n > 0 {
+					arg = n - 1
+				}
+				return i + end + 1
+			}
+			return i
+		}
+	)
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		i = index(i)
+		if i < len(format) && format[i] == '*' {
+			mark(&set)
+			i++
+		}
+		for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+			i++
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			i = index(i)
+			if i < len(format) && format[i] == '*' {
+				mark(&set)
+				i++
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				i++
+			}
+		}
+		i = index(i)
+		if i >= len(format) {
+			break
+		}
+		switch format[i] {
+		case '%':
+		case 'T', 'p':
+			mark(&set)
+		default:
+			mark(&formatted)
+		}
+	}
+	return set &^ formatted
+}
+
+// needSanitizeArg reports whether the formatted arg may need escaping.
+// The strings are checked as they are, the values that format themselves and the composites may contain anything.
+// The numbers, the bools and the pointers are left as they are, for the verbs like %*d and %p.
+func needSanitizeArg(a any) bool {
+	switch x := a.(type) {
+	case nil:
+		return false
+	case string:
+		return needSanitize(x)
+	case []byte:
+		return needSanitize(bytesToString(x))
+	case error, fmt.Stringer, fmt.Formatter:
+		return true
+	}
+	switch reflect.TypeOf(a).Kind() {
+	case reflect.String, reflect.Slice, reflect.Array, reflect.Map, reflect.Struct, reflect.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+// sanitizeArgs returns the args with the ones that may need escaping wrapped by sanitizedArg,
+// args itself if no args need.
+func sanitizeArgs(format string, args []any) []any {
+	var (
+		xs     []any
+		raw    uint64
+		parsed bool
+	)
+	for i, a := range args {
+		if !needSanitizeArg(a) {
+			continue
+		}
+		if !parsed {
+			raw, parsed = rawArgs(format), true
+		}
+		if i < 64 && raw&(1<<i) != 0 {
+			continue
+		}
+		if xs == nil {
+			xs = make([]any, len(args))
+			copy(xs, args)
+		}
+		xs[i] = sanitizedArg{v: a}
+	}
+	if xs == nil {
+		return args
+	}
+	return xs
+}
+
+// SanitizeMapper escapes the args and the name of the event by `Sanitize`,
+// for the consumers that write the message as it is like `StandardLogConsumer`.
+// The format is regarded as trusted.
+// The fields are left to the encoders, which escape them.
+func SanitizeMapper(ev Event) (Event, error) {
+	extra := ExtraOf(ev)
+	if extra.Sanitized {
+		return ev, nil
+	}
+	args := sanitizeArgs(ev.Format(), ev.Args())
+	extra.Name = Sanitize(extra.Name)
+	extra.Sanitized = true
+	return WithExtra(DeriveEvent(ev, ev.Level(), ev.Format(), args), extra), nil
+}
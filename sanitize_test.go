@@ -0,0 +1,152 @@
+package logger_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitize(t *testing.T) {
+	for _, tc := range []struct {
+		title string
+		input string
+		want  string
+	}{
+		{
+			title: "plain",
+			input: "hello, 世界",
+			want:  "hello, 世界",
+		},
+		{
+			title: "newlines",
+			input: "ok\nI | forged\r\n",
+			want:  `ok\nI | forged\r\n`,
+		},
+		{
+			title: "ansi",
+			input: "\x1b[31mred\x1b[0m",
+			want:  `\x1b[31mred\x1b[0m`,
+		},
+		{
+			title: "backslash",
+			input: `C:\n`,
+			want:  `C:\\n`,
+		},
+		{
+			title: "controls",
+			input: "a\x00\tb\x7f\u0085",
+			want:  `a\x00\tb\x7f\u0085`,
+		},
+		{
+			title: "separators and bidi",
+			input: "a\u2028b\u202ec",
+			want:  `a\u2028b\u202ec`,
+		},
+		{
+			title: "invalid utf8",
+			input: "a\xffb",
+			want:  `a\xffb`,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			got := logger.Sanitize(tc.input)
+			assert.Equal(t, tc.want, got)
+			back, err := logger.Unsanitize(got)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.input, back)
+		})
+	}
+}
+
+func TestUnsanitize(t *testing.T) {
+	_, err := logger.Unsanitize(`broken\`)
+	assert.True(t, errors.Is(err, logger.ErrInvalidSanitized))
+}
+
+func TestSanitizeMapper(t *testing.T) {
+	ev := logger.WithExtra(
+		logger.NewEvent(logger.Linfo, "user %q\nid %5d", []any{"alice\nI | admin", 12}),
+		logger.Extra{Name: "web\x1b[2J"},
+	)
+	got, err := logger.SanitizeMapper(ev)
+	assert.Nil(t, err)
+	assert.Equal(t, "user \"alice\\\\nI | admin\"\nid    12", fmt.Sprint(got))
+	assert.Equal(t, `web\x1b[2J`, logger.ExtraOf(got).Name)
+	assert.True(t, logger.ExtraOf(got).Sanitized)
+
+	t.Run("not twice", func(t *testing.T) {
+		enc := logger.NewTextEncoder(logger.EncoderConfig{})
+		b, err := enc(got)
+		assert.Nil(t, err)
+		assert.Equal(t, "I | [web\\x1b[2J] user \"alice\\\\nI | admin\"\nid    12", string(b))
+	})
+}
+
+func TestTextEncoderSanitize(t *testing.T) {
+	ev := logger.AddFields(
+		logger.WithExtra(
+			logger.NewEvent(logger.Linfo, "login %s", []any{"bob\nE | root logged in"}),
+			logger.Extra{Name: "auth"},
+		),
+		logger.Field{Key: "agent", Value: "curl\x1b[0m"},
+	)
+
+	t.Run("default", func(t *testing.T) {
+		b, err := logger.NewTextEncoder(logger.EncoderConfig{})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `I | [auth] login bob\nE | root logged in agent="curl\x1b[0m"`, string(b))
+	})
+
+	t.Run("template", func(t *testing.T) {
+		enc, err := logger.NewTemplateEncoder("{msg} {field:agent}", logger.EncoderConfig{})
+		assert.Nil(t, err)
+		b, err := enc(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `login bob\nE | root logged in curl\x1b[0m`, string(b))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		b, err := logger.NewTextEncoder(logger.EncoderConfig{NoSanitize: true})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, "I | [auth] login bob\nE | root logged in agent=\"curl\\x1b[0m\"", string(b))
+	})
+}
+
+func TestSanitizeArgsVerbs(t *testing.T) {
+	var (
+		p     = &struct{ n int }{n: 1}
+		calls int
+		lazy  = logger.LazyValue(func() any {
+			calls++
+			return "lazy\nvalue"
+		})
+		format = "w %*d t %T p %t %s %v"
+		args   = []any{5, 42, 3, p == p, "a\tb", lazy}
+		want   = fmt.Sprintf("w %*d t %T p %t %s %v", 5, 42, 3, true, `a\tb`, `lazy\nvalue`)
+	)
+
+	t.Run("default", func(t *testing.T) {
+		calls = 0
+		l := logger.NewDefault(logger.Linfo)
+		got := captureStandardLog(func() { l.Info(format, args...) })
+		assert.Equal(t, "I | "+want+"\n", got)
+		assert.Equal(t, 1, calls)
+		got = captureStandardLog(func() { l.Info("%p", p) })
+		assert.Equal(t, fmt.Sprintf("I | %p\n", p), got)
+	})
+
+	t.Run("mapper", func(t *testing.T) {
+		calls = 0
+		ev, err := logger.SanitizeMapper(logger.NewEvent(logger.Linfo, format, args))
+		assert.Nil(t, err)
+		assert.Equal(t, want, fmt.Sprintf(ev.Format(), ev.Args()...))
+		assert.Equal(t, 1, calls)
+		ev, err = logger.SanitizeMapper(logger.NewEvent(logger.Linfo, "%T %p %.[3]*[4]s %[2]T %[5]T", []any{"s\n", p, 2, errors.New("e\rr"), "t\n"}))
+		assert.Nil(t, err)
+		assert.Equal(t, fmt.Sprintf(`string %p e\r %T string`, p, p), fmt.Sprintf(ev.Format(), ev.Args()...))
+	})
+}
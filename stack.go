@@ -0,0 +1,143 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// StackConfig is the configuration of the stack traces recorded by `Logger`.
+type StackConfig struct {
+	// Level is the least severe level to record, default is Lerror.
+	Level Level
+	// Depth is the max number of the frames, default is 32.
+	Depth int
+}
+
+func (c *StackConfig) enabled(level Level) bool {
+	if c == nil || level == Lsilent {
+		return false
+	}
+	x := c.Level
+	if x == Lsilent {
+		x = Lerror
+	}
+	return level <= x
+}
+
+func (c *StackConfig) depth() int {
+	if c.Depth <= 0 {
+		return 32
+	}
+	return c.Depth
+}
+
+// StackTracer is implemented by the errors that carry the stack trace.
+//
+// The errors with the method `StackTrace()` that returns a slice of program counters,
+// like `github.com/pkg/errors`, are also regarded as StackTracer.
+type StackTracer interface {
+	StackTrace() []uintptr
+}
+
+// errorStackTrace returns the stack trace of the innermost error that carries it in the unwrap chain.
+func errorStackTrace(err error) ([]uintptr, bool) {
+	var (
+		pcs   []uintptr
+		found bool
+	)
+	for i := 0; err != nil && i < errorMaxDepth; i++ {
+		if x, ok := stackTraceOf(err); ok {
+			pcs = x
+			found = true
+		}
+		err = errors.Unwrap(err)
+	}
+	return pcs, found
+}
+
+func stackTraceOf(err error) ([]uintptr, bool) {
+	if x, ok := err.(StackTracer); ok {
+		return x.StackTrace(), true
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() {
+		return nil, false
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.Uintptr {
+		return nil, false
+	}
+	v := m.Call(nil)[0]
+	pcs := make([]uintptr, v.Len())
+	for i := range pcs {
+		pcs[i] = uintptr(v.Index(i).Uint())
+	}
+	return pcs, true
+}
+
+// captureStack returns the frames outside of this library of the current goroutine.
+func captureStack(skip, depth int) []runtime.Frame {
+	pcs := make([]uintptr, depth+16)
+	n := runtime.Callers(skip+1, pcs)
+	return stackFrames(pcs[:n], depth)
+}
+
+// stackFrames returns the frames outside of this library.
+func stackFrames(pcs []uintptr, depth int) []runtime.Frame {
+	var (
+		frames = runtime.CallersFrames(pcs)
+		r      []runtime.Frame
+	)
+	for len(r) < depth {
+		f, more := frames.Next()
+		if f.Function != "" && !isLibraryFunc(f.Function) {
+			r = append(r, f)
+		}
+		if !more {
+			break
+		}
+	}
+	return r
+}
+
+// stackOf returns the stack trace of the event by the config,
+// the one of the attached error is preferred.
+func (c *StackConfig) stackOf(err error, skip int) []runtime.Frame {
+	if err != nil {
+		if pcs, ok := errorStackTrace(err); ok {
+			return stackFrames(pcs, c.depth())
+		}
+	}
+	return captureStack(skip+1, c.depth())
+}
+
+// stackFrameJSON is a frame of the stack trace in the structured formats.
+type stackFrameJSON struct {
+	Func string `json:"func"`
+	File string `json:"file"`
+	Line int    `json:"line"`
+}
+
+func stackJSON(frames []runtime.Frame) []stackFrameJSON {
+	r := make([]stackFrameJSON, len(frames))
+	for i, f := range frames {
+		r[i] = stackFrameJSON{
+			Func: f.Function,
+			File: f.File,
+			Line: f.Line,
+		}
+	}
+	return r
+}
+
+// writeStack writes the stack trace in the indented lines like
+//
+//	at main.main (/src/main.go:10)
+func writeStack(b *bytes.Buffer, frames []runtime.Frame) {
+	for _, f := range frames {
+		fmt.Fprintf(b, "\n    at %s (%s:%d)", f.Function, f.File, f.Line)
+	}
+}
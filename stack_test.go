@@ -0,0 +1,130 @@
+package logger_test
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"log"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// frame is like the frame of github.com/pkg/errors.
+type frame uintptr
+
+type stackError struct {
+	stack []frame
+}
+
+func (stackError) Error() string { return "stack error" }
+
+func (e stackError) StackTrace() []frame { return e.stack }
+
+//go:noinline
+func newStackError() error {
+	pcs := make([]uintptr, 8)
+	n := runtime.Callers(1, pcs)
+	e := stackError{}
+	for _, pc := range pcs[:n] {
+		e.stack = append(e.stack, frame(pc))
+	}
+	return e
+}
+
+func TestStack(t *testing.T) {
+	var got logger.Event
+	l := &logger.Logger{
+		Proxy: logger.NewProxy(logger.MustNewMapperFunc(func(ev logger.Event) { got = ev })),
+		Stack: &logger.StackConfig{
+			Level: logger.Lwarn,
+			Depth: 2,
+		},
+	}
+
+	t.Run("capture", func(t *testing.T) {
+		l.Error("failed")
+		stack := logger.ExtraOf(got).Stack
+		assert.Equal(t, 2, len(stack))
+		assert.True(t, strings.HasSuffix(stack[0].Function, "logger_test.TestStack.func2"), stack[0].Function)
+		assert.True(t, strings.HasSuffix(stack[0].File, "stack_test.go"))
+	})
+
+	t.Run("level", func(t *testing.T) {
+		l.Warn("warn")
+		assert.NotEmpty(t, logger.ExtraOf(got).Stack)
+		l.Info("info")
+		assert.Empty(t, logger.ExtraOf(got).Stack)
+	})
+
+	t.Run("error stack", func(t *testing.T) {
+		l.Error("failed: %v", newStackError())
+		stack := logger.ExtraOf(got).Stack
+		assert.Equal(t, 2, len(stack))
+		assert.True(t, strings.HasSuffix(stack[0].Function, "logger_test.newStackError"), stack[0].Function)
+	})
+
+	t.Run("encode", func(t *testing.T) {
+		ev := logger.WithExtra(logger.NewEvent(logger.Lerror, "failed", nil), logger.Extra{
+			Stack: []runtime.Frame{
+				{Function: "main.run", File: "/src/main.go", Line: 20},
+				{Function: "main.main", File: "/src/main.go", Line: 10},
+			},
+		})
+		b, err := logger.NewTextEncoder(logger.EncoderConfig{})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `E | failed
+    at main.run (/src/main.go:20)
+    at main.main (/src/main.go:10)`, string(b))
+		b, err = logger.NewJSONEncoder(logger.EncoderConfig{})(ev)
+		assert.Nil(t, err)
+		assert.Equal(t, `{"level":"error","msg":"failed","stack":[{"func":"main.run","file":"/src/main.go","line":20},{"func":"main.main","file":"/src/main.go","line":10}]}`, string(b))
+	})
+}
+
+// captureStandardLog returns the output of the standard log written by f.
+func captureStandardLog(f func()) string {
+	var (
+		buf   bytes.Buffer
+		w     = log.Writer()
+		flags = log.Flags()
+	)
+	log.SetOutput(&buf)
+	log.SetFlags(0)
+	defer func() {
+		log.SetOutput(w)
+		log.SetFlags(flags)
+	}()
+	f()
+	return buf.String()
+}
+
+func TestStackDefault(t *testing.T) {
+	err := fmt.Errorf("wrap: %w", fs.ErrNotExist)
+
+	t.Run("a line without stack", func(t *testing.T) {
+		l := logger.NewDefault(logger.Linfo)
+		got := captureStandardLog(func() {
+			l.Error("failed: %v", err)
+		})
+		assert.Equal(t, "E | failed: wrap: file does not exist\n", got)
+	})
+
+	t.Run("stack", func(t *testing.T) {
+		l := logger.NewDefault(logger.Linfo)
+		l.Stack = &logger.StackConfig{Depth: 1}
+		got := captureStandardLog(func() {
+			l.Error("failed: %v", err)
+		})
+		lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+		if assert.Equal(t, 4, len(lines), got) {
+			assert.Equal(t, "E | failed: wrap: file does not exist", lines[0])
+			assert.Equal(t, "    error: wrap: file does not exist (*fmt.wrapError)", lines[1])
+			assert.Equal(t, "        cause: file does not exist (*errors.errorString)", lines[2])
+			assert.True(t, strings.HasPrefix(lines[3], "    at github.com/berquerant/logger_test.TestStackDefault.func2.1 ("), lines[3])
+		}
+	})
+}
@@ -0,0 +1,258 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const formatTemplate = "template"
+
+// Builtin stages:
+//
+//	level:  filters events by level.                  params: level (default "info")
+//	prefix: adds a prefix depending on the level, see `LogLevelToPrefixMapper`.
+//	encode: replaces the message with the encoded one. params: format (default "text"), time_format, caller, color, template
+//	stdlog: writes events by `log.Printf`.
+//	writer: writes events with a newline.              params: output (default "stderr"), format, time_format, caller, color, template
+//	sample: drops a part of events, see `Sampler`.     params: mode (burst or random, default burst),
+//	        first (default 10), thereafter (default 100), interval (default "1s"), rate (default 0.1),
+//	        keep_level (default "error"), report_key
+//	ratelimit: drops events over the rate, see `RateLimiter`. params: rate (default 10), burst (default 10),
+//	        key (level_format (default), level, format or field:KEY), summarize (default true), summary_interval (default "10s")
+//	dedup:  collapses the repeated events, see `Deduper`. params: window (default "10s"),
+//	        fields (compares the format and the fields instead of the message)
+//	redact: masks the secrets, see `Redaction`.        params: keys, patterns, builtin (email, card, bearer),
+//	        mode (replace (default), partial or hash), replacement, keep, hash_key
+//
+// format is text, json, logfmt or template, template requires the template param, see `NewTemplateEncoder`.
+// color is auto, always or never, only for the text format and the template.
+// encode regards auto as never because the destination is unknown.
+// writer without format writes the message as is, mainly the output of encode.
+func builtinStages() map[string]StageBuilder {
+	return map[string]StageBuilder{
+		"level":     buildLevelStage,
+		"prefix":    buildPrefixStage,
+		"encode":    buildEncodeStage,
+		"stdlog":    buildStdlogStage,
+		"writer":    buildWriterStage,
+		"sample":    buildSampleStage,
+		"ratelimit": buildRateLimitStage,
+		"dedup":     buildDedupStage,
+		"redact":    buildRedactStage,
+		"sanitize":  buildSanitizeStage,
+	}
+}
+
+func buildLevelStage(p *Params) (*Stage, error) {
+	level := p.Level("level", Linfo)
+	return &Stage{
+		Mapper:  LogLevelFilter(level),
+		Enabled: func(x Level) bool { return x <= level },
+	}, nil
+}
+
+func buildPrefixStage(_ *Params) (*Stage, error) {
+	return &Stage{
+		Mapper: LogLevelToPrefixMapper,
+	}, nil
+}
+
+func buildSanitizeStage(_ *Params) (*Stage, error) {
+	return &Stage{
+		Mapper: SanitizeMapper,
+	}, nil
+}
+
+func buildStdlogStage(_ *Params) (*Stage, error) {
+	return &Stage{
+		Mapper: StandardLogConsumer,
+	}, nil
+}
+
+func encoderFromParams(p *Params, defaultFormat string, w io.Writer) (Encoder, error) {
+	format := p.String("format", defaultFormat)
+	fields, err := ParseFieldsStyle(p.String("fields", ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg := EncoderConfig{
+		TimeFormat: ParseTimeFormat(p.String("time_format", "")),
+		Caller:     p.Bool("caller", false),
+		NoSanitize: !p.Bool("sanitize", true),
+		Fields:     fields,
+		FieldsKey:  p.String("fields_key", ""),
+	}
+	color, err := ParseColorMode(p.String("color", string(ColorAuto)))
+	if err != nil {
+		return nil, err
+	}
+	if w != nil {
+		cfg.Theme = color.Theme(w, DefaultTheme)
+	} else if color == ColorAlways {
+		cfg.Theme = DefaultTheme
+	}
+	tmpl := p.String("template", "")
+	switch format {
+	case "":
+		return RawEncoder, nil
+	case formatTemplate:
+		if tmpl == "" {
+			return nil, fmt.Errorf("%w template: required by format template", ErrInvalidParam)
+		}
+		return NewTemplateEncoder(tmpl, cfg)
+	}
+	f, err := ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	return NewEncoder(f, cfg)
+}
+
+func buildEncodeStage(p *Params) (*Stage, error) {
+	enc, err := encoderFromParams(p, string(FormatText), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &Stage{
+		Mapper: NewEncodeMapper(enc),
+	}, nil
+}
+
+func buildWriterStage(p *Params) (*Stage, error) {
+	output := p.String("output", OutputStderr)
+	w, closer, err := openOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	enc, err := encoderFromParams(p, "", w)
+	if err == nil {
+		err = p.Err()
+	}
+	if err != nil {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+	s := &Stage{
+		Mapper: NewWriterConsumer(w, enc),
+	}
+	if closer != nil {
+		s.Close = closer.Close
+	}
+	return s, nil
+}
+
+func buildSampleStage(p *Params) (*Stage, error) {
+	var (
+		mode       = p.String("mode", "burst")
+		first      = p.Int("first", 10)
+		thereafter = p.Int("thereafter", 100)
+		interval   = p.Duration("interval", time.Second)
+		rate       = p.Float("rate", 0.1)
+		cfg        = SamplerConfig{
+			KeepLevel: p.Level("keep_level", Lerror),
+			ReportKey: p.String("report_key", ""),
+		}
+	)
+	switch mode {
+	case "burst":
+		return &Stage{
+			Mapper: NewBurstSampler(first, thereafter, interval, cfg).Mapper,
+		}, nil
+	case "random":
+		return &Stage{
+			Mapper: NewRandomSampler(rate, cfg).Mapper,
+		}, nil
+	default:
+		return nil, fmt.Errorf("%w mode: want burst or random but got %s", ErrInvalidParam, mode)
+	}
+}
+
+func buildRateLimitStage(p *Params) (*Stage, error) {
+	var (
+		key = p.String("key", "level_format")
+		cfg = RateLimiterConfig{
+			Rate:            p.Float("rate", 10),
+			Burst:           p.Int("burst", 10),
+			Summarize:       p.Bool("summarize", true),
+			SummaryInterval: p.Duration("summary_interval", 10*time.Second),
+		}
+	)
+	switch {
+	case key == "level_format":
+		cfg.Key = RateKeyByLevelAndFormat
+	case key == "level":
+		cfg.Key = RateKeyByLevel
+	case key == "format":
+		cfg.Key = RateKeyByFormat
+	case strings.HasPrefix(key, "field:") && len(key) > len("field:"):
+		cfg.Key = RateKeyByField(strings.TrimPrefix(key, "field:"))
+	default:
+		return nil, fmt.Errorf("%w key: want level_format, level, format or field:KEY but got %s", ErrInvalidParam, key)
+	}
+	if err := p.Err(); err != nil {
+		// avoid starting the goroutine for the invalid stage
+		return nil, err
+	}
+	return NewRateLimiter(cfg).Stage(), nil
+}
+
+func buildDedupStage(p *Params) (*Stage, error) {
+	cfg := DeduperConfig{
+		Window: p.Duration("window", 10*time.Second),
+	}
+	if p.Has("fields") {
+		cfg.Key = DedupKeyByFormatAndFields(p.Strings("fields", nil)...)
+	}
+	return NewDeduper(cfg).Stage(), nil
+}
+
+var redactBuiltinPatterns = map[string]*regexp.Regexp{
+	"email":  RedactPatternEmail,
+	"card":   RedactPatternCard,
+	"bearer": RedactPatternBearer,
+}
+
+func buildRedactStage(p *Params) (*Stage, error) {
+	cfg := RedactConfig{
+		Keys:        p.Strings("keys", nil),
+		Replacement: p.String("replacement", ""),
+		Keep:        p.Int("keep", 0),
+		HashKey:     []byte(p.String("hash_key", "")),
+	}
+	switch mode := p.String("mode", "replace"); mode {
+	case "replace":
+		cfg.Mode = RedactReplace
+	case "partial":
+		cfg.Mode = RedactPartial
+	case "hash":
+		cfg.Mode = RedactHash
+	default:
+		return nil, fmt.Errorf("%w mode: want replace, partial or hash but got %s", ErrInvalidParam, mode)
+	}
+	for _, name := range p.Strings("builtin", nil) {
+		x, ok := redactBuiltinPatterns[name]
+		if !ok {
+			return nil, fmt.Errorf("%w builtin: unknown pattern %s", ErrInvalidParam, name)
+		}
+		cfg.Patterns = append(cfg.Patterns, x)
+	}
+	for _, pattern := range p.Strings("patterns", nil) {
+		x, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("%w patterns: %v", ErrInvalidParam, err)
+		}
+		cfg.Patterns = append(cfg.Patterns, x)
+	}
+	r, err := NewRedaction(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Stage{
+		Mapper: r.Mapper,
+	}, nil
+}
@@ -0,0 +1,268 @@
+package logger
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+var ErrInvalidTemplate = errors.New("InvalidTemplate")
+
+// NewTemplateEncoder returns an encoder that writes the line by the template like
+//
+//	{time:RFC3339} {level:short} [{name}] {msg} {fields}
+//
+// The placeholders are {key}, {key:arg} and the options separated by "|" like {name|pad=8|trunc=8}.
+//
+//	time:LAYOUT   the time, LAYOUT is a layout or the name like RFC3339, default RFC3339.
+//	level:STYLE   the level, STYLE is name (default) like "info", short like "I" or prefix like "I |".
+//	name          the name of the logger.
+//	msg           the formatted message.
+//	caller:STYLE  the call site, STYLE is short (default) like "main.go:10" or long for the full path.
+//	fields:STYLE  the fields, STYLE is empty (default) like "key=value key2=value2" or json like {"key":"value"}.
+//	field:KEY     the value of the field.
+//
+// The options:
+//
+//	pad=N    pads with spaces to the width N, left-aligned.
+//	lpad=N   pads with spaces to the width N, right-aligned.
+//	trunc=N  truncates to N characters.
+//
+// "{{" and "}}" are the literal braces.
+// The colors of cfg.Theme apply to time, level, name, caller and the keys of fields.
+// name, msg, the keys of fields and field are escaped by `Sanitize` unless cfg.NoSanitize.
+// The other fields of cfg are not used.
+func NewTemplateEncoder(tmpl string, cfg EncoderConfig) (Encoder, error) {
+	segments, err := parseTemplate(tmpl, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return func(ev Event) ([]byte, error) {
+		var (
+			b     bytes.Buffer
+			extra = ExtraOf(ev)
+		)
+		for _, s := range segments {
+			s(&b, ev, extra)
+		}
+		return b.Bytes(), nil
+	}, nil
+}
+
+type templateSegment func(b *bytes.Buffer, ev Event, extra Extra)
+
+func parseTemplate(tmpl string, cfg EncoderConfig) ([]templateSegment, error) {
+	var (
+		segments []templateSegment
+		literal  strings.Builder
+		flush    = func() {
+			if literal.Len() == 0 {
+				return
+			}
+			s := literal.String()
+			literal.Reset()
+			segments = append(segments, func(b *bytes.Buffer, _ Event, _ Extra) { b.WriteString(s) })
+		}
+	)
+	for i := 0; i < len(tmpl); i++ {
+		c := tmpl[i]
+		switch {
+		case c == '{' && strings.HasPrefix(tmpl[i:], "{{"):
+			literal.WriteByte('{')
+			i++
+		case c == '}' && strings.HasPrefix(tmpl[i:], "}}"):
+			literal.WriteByte('}')
+			i++
+		case c == '}':
+			return nil, fmt.Errorf("%w: unexpected } at %d", ErrInvalidTemplate, i)
+		case c == '{':
+			end := strings.IndexByte(tmpl[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("%w: unclosed { at %d", ErrInvalidTemplate, i)
+			}
+			seg, err := parsePlaceholder(tmpl[i+1:i+end], cfg)
+			if err != nil {
+				return nil, fmt.Errorf("%w: at %d: %v", ErrInvalidTemplate, i, err)
+			}
+			flush()
+			segments = append(segments, seg)
+			i += end
+		default:
+			literal.WriteByte(c)
+		}
+	}
+	flush()
+	return segments, nil
+}
+
+type templateValue func(ev Event, extra Extra) string
+
+func parsePlaceholder(s string, cfg EncoderConfig) (templateSegment, error) {
+	var (
+		theme       = cfg.Theme
+		options     = strings.Split(s, "|")
+		key, arg, _ = strings.Cut(options[0], ":")
+		value       templateValue
+		color       func(ev Event) string
+		constColor  = func(c string) func(Event) string { return func(Event) string { return c } }
+	)
+	switch key {
+	case "time":
+		layout := time.RFC3339
+		if arg != "" {
+			layout = ParseTimeFormat(arg)
+		}
+		value = func(_ Event, extra Extra) string {
+			if extra.Time.IsZero() {
+				return ""
+			}
+			return extra.Time.Format(layout)
+		}
+		color = constColor(theme.get(func(t *Theme) string { return t.Time }))
+	case "level":
+		switch arg {
+		case "", "name":
+			value = func(ev Event, _ Extra) string { return ev.Level().String() }
+		case "short":
+			value = func(ev Event, _ Extra) string {
+				if x, ok := LookupLevel(ev.Level()); ok {
+					return x.Short
+				}
+				return "?"
+			}
+		case "prefix":
+			value = func(ev Event, _ Extra) string { return logLevelToPrefix(ev.Level()) }
+		default:
+			return nil, fmt.Errorf("unknown level style %q", arg)
+		}
+		color = func(ev Event) string { return theme.level(ev.Level()) }
+	case "name":
+		value = func(_ Event, extra Extra) string { return cfg.sanitizeMessage(extra, extra.Name) }
+		color = constColor(theme.get(func(t *Theme) string { return t.Name }))
+	case "msg":
+		value = func(ev Event, extra Extra) string {
+			return cfg.sanitizeMessage(extra, fmt.Sprintf(ev.Format(), ev.Args()...))
+		}
+	case "caller":
+		var long bool
+		switch arg {
+		case "", "short":
+		case "long":
+			long = true
+		default:
+			return nil, fmt.Errorf("unknown caller style %q", arg)
+		}
+		value = func(_ Event, extra Extra) string {
+			f, ok := extra.Frame()
+			if !ok {
+				return ""
+			}
+			file := f.File
+			if !long {
+				file = filepath.Base(file)
+			}
+			return fmt.Sprintf("%s:%d", file, f.Line)
+		}
+		color = constColor(theme.get(func(t *Theme) string { return t.Caller }))
+	case "fields":
+		keyColor := theme.get(func(t *Theme) string { return t.Key })
+		switch arg {
+		case "":
+		case string(FieldsJSON):
+			value = func(_ Event, extra Extra) string {
+				if len(extra.Fields) == 0 {
+					return ""
+				}
+				b, err := fieldsJSON(extra.Fields)
+				if err != nil {
+					return ""
+				}
+				return string(b)
+			}
+		default:
+			return nil, fmt.Errorf("unknown fields style %q", arg)
+		}
+		if value != nil {
+			break
+		}
+		value = func(_ Event, extra Extra) string {
+			var b bytes.Buffer
+			for i, f := range extra.Fields {
+				if i > 0 {
+					b.WriteByte(' ')
+				}
+				paint(&b, keyColor, cfg.sanitize(f.Key)+"=")
+				b.WriteString(logfmtValue(fieldString(f.Value)))
+			}
+			return b.String()
+		}
+	case "field":
+		if arg == "" {
+			return nil, errors.New("field requires the key like {field:request_id}")
+		}
+		value = func(_ Event, extra Extra) string {
+			for _, f := range extra.Fields {
+				if f.Key == arg {
+					return cfg.sanitize(fieldString(f.Value))
+				}
+			}
+			return ""
+		}
+	default:
+		return nil, fmt.Errorf("unknown key %q", key)
+	}
+
+	var pad, lpad, trunc int
+	for _, opt := range options[1:] {
+		name, v, ok := strings.Cut(opt, "=")
+		n, err := strconv.Atoi(v)
+		if !ok || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid option %q of %s", opt, key)
+		}
+		switch name {
+		case "pad":
+			pad = n
+		case "lpad":
+			lpad = n
+		case "trunc":
+			trunc = n
+		default:
+			return nil, fmt.Errorf("unknown option %q of %s", opt, key)
+		}
+	}
+
+	return func(b *bytes.Buffer, ev Event, extra Extra) {
+		s := value(ev, extra)
+		if trunc > 0 && utf8.RuneCountInString(s) > trunc {
+			s = string([]rune(s)[:trunc])
+		}
+		var (
+			width   = utf8.RuneCountInString(s)
+			spaces  string
+			padLeft bool
+		)
+		switch {
+		case lpad > width:
+			spaces = strings.Repeat(" ", lpad-width)
+			padLeft = true
+		case pad > width:
+			spaces = strings.Repeat(" ", pad-width)
+		}
+		if padLeft {
+			b.WriteString(spaces)
+		}
+		if color != nil && s != "" {
+			paint(b, color(ev), s)
+		} else {
+			b.WriteString(s)
+		}
+		if !padLeft {
+			b.WriteString(spaces)
+		}
+	}, nil
+}
@@ -0,0 +1,106 @@
+package logger_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/berquerant/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateEncoder(t *testing.T) {
+	ev := logger.WithExtra(
+		logger.NewEvent(logger.Lwarn, "disk %s", []any{"is full"}),
+		logger.Extra{
+			Time: time.Date(2022, 9, 20, 10, 0, 0, 0, time.UTC),
+			Name: "storage",
+			Fields: []logger.Field{
+				{Key: "path", Value: "/var"},
+				{Key: "usage", Value: 0.99},
+			},
+		},
+	)
+
+	for _, tc := range []struct {
+		title string
+		tmpl  string
+		want  string
+		err   bool
+	}{
+		{
+			title: "example",
+			tmpl:  "{time:RFC3339} {level:short} [{name}] {msg} {fields}",
+			want:  "2022-09-20T10:00:00Z W [storage] disk is full path=/var usage=0.99",
+		},
+		{
+			title: "time layout and prefix",
+			tmpl:  "{time:15:04:05} {level:prefix} {msg}",
+			want:  "10:00:00 W | disk is full",
+		},
+		{
+			title: "padding",
+			tmpl:  "{level|pad=6}|{name|lpad=10}|",
+			want:  "warn  |   storage|",
+		},
+		{
+			title: "truncation",
+			tmpl:  "{name|trunc=4|pad=6}{msg|trunc=4}",
+			want:  "stor  disk",
+		},
+		{
+			title: "field and braces",
+			tmpl:  "{{{field:path}}} {field:missing}",
+			want:  "{/var} ",
+		},
+		{
+			title: "unknown key",
+			tmpl:  "{message}",
+			err:   true,
+		},
+		{
+			title: "unknown option",
+			tmpl:  "{msg|width=3}",
+			err:   true,
+		},
+		{
+			title: "invalid option value",
+			tmpl:  "{msg|pad=x}",
+			err:   true,
+		},
+		{
+			title: "unknown level style",
+			tmpl:  "{level:long}",
+			err:   true,
+		},
+		{
+			title: "field without key",
+			tmpl:  "{field}",
+			err:   true,
+		},
+		{
+			title: "unclosed",
+			tmpl:  "{msg",
+			err:   true,
+		},
+		{
+			title: "unexpected close",
+			tmpl:  "msg}",
+			err:   true,
+		},
+	} {
+		tc := tc
+		t.Run(tc.title, func(t *testing.T) {
+			enc, err := logger.NewTemplateEncoder(tc.tmpl, logger.EncoderConfig{})
+			if tc.err {
+				assert.ErrorIs(t, err, logger.ErrInvalidTemplate)
+				return
+			}
+			if !assert.Nil(t, err) {
+				return
+			}
+			got, err := enc(ev)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
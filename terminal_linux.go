@@ -0,0 +1,19 @@
+package logger
+
+import (
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// isTerminal returns true if the terminal attributes of f are available.
+func isTerminal(f *os.File) bool {
+	var termios syscall.Termios
+	_, _, errno := syscall.Syscall(
+		syscall.SYS_IOCTL,
+		f.Fd(),
+		uintptr(syscall.TCGETS),
+		uintptr(unsafe.Pointer(&termios)),
+	)
+	return errno == 0
+}
@@ -0,0 +1,12 @@
+//go:build !linux
+
+package logger
+
+import "os"
+
+// isTerminal returns true if f is a character device.
+// This is an approximation without the platform-specific syscalls.
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}